@@ -6,17 +6,25 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"path"
 	"syscall"
-	"time"
 
 	awsconfig "github.com/aws/aws-sdk-go-v2/config"
 	"github.com/patrickmn/go-cache"
 
 	"jet-example/internal/config"
+	"jet-example/internal/domain"
 	"jet-example/internal/fetcher/salesforce"
 	"jet-example/internal/scheduler"
+	localTokenStore "jet-example/internal/tokenstore/local"
+	memoryTokenStore "jet-example/internal/tokenstore/memory"
+	s3TokenStore "jet-example/internal/tokenstore/s3"
 	"jet-example/internal/uploader/s3"
+	localWatermark "jet-example/internal/watermark/local"
+	s3Watermark "jet-example/internal/watermark/s3"
+	"jet-example/pkg/logging"
 	pkgS3 "jet-example/pkg/s3_client"
+	"jet-example/pkg/transport"
 )
 
 func main() {
@@ -30,35 +38,150 @@ func main() {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	// initialize dependencies
+	// initialize dependencies, without transport.Retry: the Salesforce client retries its calls itself
+	middlewares := []transport.Middleware{
+		transport.RequestID(),
+		transport.UserAgent(cfg.HTTPClient.UserAgent),
+	}
+	if cfg.HTTPClient.LogRequests {
+		logger := logging.NewStdLogger(log.Default())
+		middlewares = append(middlewares, transport.Logging(logger))
+	}
 	httpClient := &http.Client{
-		Timeout: 10 * time.Second,
+		Timeout:   cfg.HTTPClient.Timeout,
+		Transport: transport.Chain(http.DefaultTransport, middlewares...),
 	}
 	cacheClient := cache.New(
 		cfg.CacheConfig.DefaultExpirationTime,
 		cfg.CacheConfig.CleanupInterval,
 	)
+	awsCfg, _ := awsconfig.LoadDefaultConfig(ctx)
+	s3Client := pkgS3.NewS3Client(awsCfg, cfg.S3ClientConfig)
+
+	var tokenStore domain.TokenStore
+	switch cfg.TokenStore.Backend {
+	case "memory":
+		tokenStore = memoryTokenStore.NewTokenStore(cacheClient)
+	case "local":
+		tokenStore, err = localTokenStore.NewTokenStore(
+			cfg.TokenStore.FilePath,
+			cfg.TokenStore.EncryptionKey,
+		)
+		if err != nil {
+			log.Fatalf("failed to create token store: %v", err)
+		}
+	case "s3":
+		tokenStore = s3TokenStore.NewTokenStore(
+			cfg.S3.Bucket,
+			cfg.TokenStore.S3Prefix,
+			s3Client,
+		)
+	default:
+		log.Fatalf("unknown token store backend: %q", cfg.TokenStore.Backend)
+	}
+
+	// rotated refresh tokens replace the configured one, they must survive the process
+	if cfg.Salesforce.OAuth.GrantType == salesforce.GrantRefreshToken && cfg.TokenStore.Backend == "memory" {
+		log.Println("refresh tokens are lost on exit with the memory token store, use the local or s3 backend")
+	}
+
 	sfClient := salesforce.NewSalesforceClient(
 		cfg.Salesforce,
 		httpClient,
 		cacheClient,
+		tokenStore,
 	)
-	awsCfg, _ := awsconfig.LoadDefaultConfig(ctx)
-	s3Client := pkgS3.NewS3Client(awsCfg, cfg.S3ClientConfig)
 	s3Uploader := s3.NewS3Uploader(
-		cfg.S3.PathPrefix,
 		cfg.S3.Bucket,
+		cfg.S3.PathPrefix,
 		s3Client,
 	)
 
+	var watermarkStore domain.WatermarkStore
+	switch cfg.Watermark.Backend {
+	case "local":
+		watermarkStore = localWatermark.NewWatermarkStore(cfg.Watermark.FilePath)
+	case "s3":
+		watermarkStore = s3Watermark.NewWatermarkStore(
+			cfg.S3.Bucket,
+			cfg.Watermark.S3Key,
+			s3Client,
+		)
+	default:
+		log.Fatalf("unknown watermark backend: %q", cfg.Watermark.Backend)
+	}
+
 	// create and start the scheduler
-	s := scheduler.NewScheduler(sfClient, s3Uploader)
+	s := scheduler.NewScheduler(
+		cfg.Scheduler,
+		sfClient,
+		s3Uploader,
+		watermarkStore,
+	)
+
+	// sync a list of assets once instead of scheduling the catalog sync
+	if len(os.Args) > 1 && os.Args[1] == "sync-assets" {
+		if err := syncAssets(ctx, s, os.Args[2:]); err != nil {
+			log.Fatalf("failed to sync assets: %v", err)
+		}
+		return
+	}
+
+	// publish the content blocks to another business unit once
+	if len(os.Args) > 1 && os.Args[1] == "publish" {
+		newPublisher := func(dryRun bool) domain.Uploader {
+			publishConfig := cfg.Salesforce
+			publishConfig.Publish.DryRun = dryRun
+			// the process cache keys tokens by MID only and the target may be another tenant
+			// with the same MIDs, the token store keys them by client ID too so it is shared
+			return salesforce.NewPublisher(
+				publishConfig,
+				httpClient,
+				cache.New(cfg.CacheConfig.DefaultExpirationTime, cfg.CacheConfig.CleanupInterval),
+				tokenStore,
+			)
+		}
+		if err := publish(ctx, sfClient, newPublisher, cfg.Salesforce.Publish.DryRun, os.Args[2:]); err != nil {
+			log.Fatalf("failed to publish content blocks: %v", err)
+		}
+		return
+	}
+
 	go func() {
 		if err := s.Start(ctx); err != nil {
 			log.Fatalf("failed to start scheduler: %v", err)
 		}
 	}()
 
+	// Data Extension rows are synced next to the content blocks, always in full.
+	// Rows have no modification date, references or deletions to track.
+	var dataExtensionScheduler *scheduler.Scheduler
+	if len(cfg.Salesforce.DataExtensions.Keys) > 0 {
+		dataExtensionClient, err := salesforce.NewDataExtensionClient(sfClient)
+		if err != nil {
+			log.Fatalf("failed to create Data Extension client: %v", err)
+		}
+		dataExtensionConfig := cfg.Scheduler
+		dataExtensionConfig.FullSync = true
+		dataExtensionConfig.DetectDeletions = false
+		dataExtensionConfig.DependencyGraph = false
+		dataExtensionScheduler = scheduler.NewScheduler(
+			dataExtensionConfig,
+			dataExtensionClient,
+			s3.NewS3Uploader(
+				cfg.S3.Bucket,
+				path.Join(cfg.S3.PathPrefix, "data-extensions"),
+				s3Client,
+			),
+			watermarkStore,
+		)
+		go func() {
+			if err := dataExtensionScheduler.Start(ctx); err != nil {
+				log.Fatalf("failed to start Data Extension scheduler: %v", err)
+			}
+		}()
+	}
+
 	// graceful shutdown
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
@@ -66,4 +189,7 @@ func main() {
 	log.Println("Shutting down...")
 
 	s.Stop()
+	if dataExtensionScheduler != nil {
+		dataExtensionScheduler.Stop()
+	}
 }
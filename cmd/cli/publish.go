@@ -0,0 +1,35 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+
+	"jet-example/internal/domain"
+)
+
+// publish copies the content blocks of the source business units to the target business
+// unit once, e.g. from staging to production. The plan is only logged unless -dry-run=false:
+//
+//	cli publish -dry-run=false
+func publish(
+	ctx context.Context,
+	fetcher domain.Fetcher,
+	newPublisher func(dryRun bool) domain.Uploader,
+	defaultDryRun bool,
+	args []string,
+) error {
+	flags := flag.NewFlagSet("publish", flag.ContinueOnError)
+	dryRun := flags.Bool("dry-run", defaultDryRun, "only log the plan of creates, updates and skips")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	// a partial catalog is not published, the plan would miss the failed pages
+	contentBlocks, err := fetcher.FetchContentBlocks(ctx, domain.ContentBlocksRequest{})
+	if err != nil {
+		return fmt.Errorf("failed to fetch content blocks: %w", err)
+	}
+
+	return newPublisher(*dryRun).UploadContentBlocks(ctx, contentBlocks)
+}
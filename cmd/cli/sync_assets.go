@@ -0,0 +1,59 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"jet-example/internal/scheduler"
+)
+
+// syncAssets syncs a list of assets once instead of running the scheduler e.g.
+//
+//	cli sync-assets -ids 1234,5678 -keys newsletter-footer
+func syncAssets(ctx context.Context, s *scheduler.Scheduler, args []string) error {
+	flags := flag.NewFlagSet("sync-assets", flag.ContinueOnError)
+	ids := flags.String("ids", "", "comma separated asset IDs")
+	keys := flags.String("keys", "", "comma separated asset customer keys")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	refs, err := assetRefs(*ids, *keys)
+	if err != nil {
+		return err
+	}
+	if len(refs) == 0 {
+		return errors.New("no asset given, use -ids or -keys")
+	}
+
+	return s.SyncAssets(ctx, refs)
+}
+
+func assetRefs(ids, keys string) ([]scheduler.AssetRef, error) {
+	var refs []scheduler.AssetRef
+	for _, id := range splitList(ids) {
+		assetID, err := strconv.Atoi(id)
+		if err != nil || assetID <= 0 {
+			return nil, fmt.Errorf("invalid asset ID %q", id)
+		}
+		refs = append(refs, scheduler.AssetRef{ID: assetID})
+	}
+	for _, key := range splitList(keys) {
+		refs = append(refs, scheduler.AssetRef{CustomerKey: key})
+	}
+	return refs, nil
+}
+
+func splitList(list string) []string {
+	var items []string
+	for _, item := range strings.Split(list, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
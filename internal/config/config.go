@@ -1,9 +1,13 @@
 package config
 
 import (
+	"fmt"
+	"log"
+
 	"github.com/caarlos0/env/v10"
 
 	"jet-example/internal/fetcher/salesforce"
+	"jet-example/internal/scheduler"
 	"jet-example/internal/uploader/s3"
 	"jet-example/pkg/s3_client"
 )
@@ -12,7 +16,11 @@ type AppConfig struct {
 	Salesforce     salesforce.Config
 	S3             s3.Config
 	CacheConfig    CacheConfig
+	HTTPClient     HTTPClientConfig
 	S3ClientConfig s3_client.ClientConf
+	Scheduler      scheduler.Config
+	Watermark      WatermarkConfig
+	TokenStore     TokenStoreConfig
 }
 
 func LoadAppConfig() (AppConfig, error) {
@@ -22,5 +30,18 @@ func LoadAppConfig() (AppConfig, error) {
 		return AppConfig{}, err
 	}
 
+	if err := appConfig.Salesforce.Validate(); err != nil {
+		return AppConfig{}, fmt.Errorf("invalid Salesforce configuration: %w", err)
+	}
+
+	// files are only downloaded for the binary assets the run fetches
+	if appConfig.Scheduler.DownloadFiles && len(appConfig.Salesforce.Filter.IncludeAssetTypes) > 0 {
+		log.Printf(
+			"SYNC_DOWNLOAD_FILES only downloads the files of fetched assets, "+
+				"make sure SALESFORCE_INCLUDE_ASSET_TYPES %v lists their types (e.g. png, jpg, pdf)",
+			appConfig.Salesforce.Filter.IncludeAssetTypes,
+		)
+	}
+
 	return appConfig, nil
 }
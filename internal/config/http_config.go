@@ -0,0 +1,10 @@
+package config
+
+import "time"
+
+type HTTPClientConfig struct {
+	Timeout   time.Duration `env:"HTTP_TIMEOUT" envDefault:"10s"`
+	UserAgent string        `env:"HTTP_USER_AGENT" envDefault:"jet-example"`
+	// LogRequests logs every SFMC request, credentials are redacted
+	LogRequests bool `env:"HTTP_LOG_REQUESTS" envDefault:"false"`
+}
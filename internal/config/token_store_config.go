@@ -0,0 +1,10 @@
+package config
+
+type TokenStoreConfig struct {
+	// Backend is where access tokens are shared between runs, either "memory", "local" or "s3"
+	Backend  string `env:"TOKEN_STORE_BACKEND" envDefault:"memory"`
+	FilePath string `env:"TOKEN_STORE_FILE_PATH" envDefault:"tokens.enc"`
+	// EncryptionKey is the key the local tokens file is encrypted with, 32 random bytes in base64
+	EncryptionKey string `env:"TOKEN_STORE_ENCRYPTION_KEY"`
+	S3Prefix      string `env:"TOKEN_STORE_S3_PREFIX" envDefault:"tokens"`
+}
@@ -0,0 +1,8 @@
+package config
+
+type WatermarkConfig struct {
+	// Backend is where the watermark is persisted, either "s3" or "local"
+	Backend  string `env:"WATERMARK_BACKEND" envDefault:"s3"`
+	FilePath string `env:"WATERMARK_FILE_PATH" envDefault:"watermark.json"`
+	S3Key    string `env:"WATERMARK_S3_KEY" envDefault:"watermark.json"`
+}
@@ -0,0 +1,177 @@
+package domain
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Asset is a Content Builder asset as returned by the SFMC asset API.
+// Raw is what is written back on marshalling, so snapshots keep everything SFMC
+// returned, changes to the typed fields are not written back.
+type Asset struct {
+	ID           int          `json:"id"`
+	CustomerKey  string       `json:"customerKey,omitempty"`
+	ObjectID     string       `json:"objectID,omitempty"`
+	Name         string       `json:"name,omitempty"`
+	Description  string       `json:"description,omitempty"`
+	AssetType    AssetType    `json:"assetType"`
+	Category     Category     `json:"category"`
+	Version      int          `json:"version,omitempty"`
+	Status       *AssetStatus `json:"status,omitempty"`
+	Owner        *AssetUser   `json:"owner,omitempty"`
+	CreatedDate  time.Time    `json:"createdDate"`
+	CreatedBy    *AssetUser   `json:"createdBy,omitempty"`
+	ModifiedDate time.Time    `json:"modifiedDate"`
+	ModifiedBy   *AssetUser   `json:"modifiedBy,omitempty"`
+	EnterpriseID int          `json:"enterpriseId,omitempty"`
+	MemberID     int          `json:"memberId,omitempty"`
+	// BusinessUnitID is the MID of the business unit the asset was fetched from,
+	// which differs from MemberID for assets shared by a parent business unit
+	BusinessUnitID int                  `json:"businessUnitId,omitempty"`
+	Content        string               `json:"content,omitempty"`
+	Views          map[string]AssetView `json:"views,omitempty"`
+	Slots          map[string]Slot      `json:"slots,omitempty"`
+	// FileProperties is only set for binary assets e.g. images and documents
+	FileProperties *FileProperties `json:"fileProperties,omitempty"`
+	// Composition is only set for emails and templates when the fetcher resolves their slots
+	Composition *Composition `json:"composition,omitempty"`
+
+	// Raw is the asset JSON exactly as received from SFMC
+	Raw json.RawMessage `json:"-"`
+}
+
+type AssetType struct {
+	ID          int    `json:"id"`
+	Name        string `json:"name,omitempty"`
+	DisplayName string `json:"displayName,omitempty"`
+}
+
+type Category struct {
+	ID       int    `json:"id"`
+	Name     string `json:"name,omitempty"`
+	ParentID int    `json:"parentId,omitempty"`
+	// Path is the Content Builder folder path e.g. "Content Builder/Newsletters/Footers",
+	// resolved by the fetcher as SFMC only returns the category ID
+	Path string `json:"path,omitempty"`
+}
+
+type AssetStatus struct {
+	ID   int    `json:"id"`
+	Name string `json:"name,omitempty"`
+}
+
+type AssetUser struct {
+	ID     int    `json:"id"`
+	Email  string `json:"email,omitempty"`
+	Name   string `json:"name,omitempty"`
+	UserID string `json:"userId,omitempty"`
+}
+
+type FileProperties struct {
+	FileName     string `json:"fileName,omitempty"`
+	Extension    string `json:"extension,omitempty"`
+	FileSize     int64  `json:"fileSize,omitempty"`
+	PublishedURL string `json:"publishedURL,omitempty"`
+}
+
+// AssetView is one rendition of an asset e.g. html, text, preheader
+type AssetView struct {
+	Content string          `json:"content,omitempty"`
+	Slots   map[string]Slot `json:"slots,omitempty"`
+}
+
+// Slot is a drop zone of a view holding embedded blocks keyed by their slot block key
+type Slot struct {
+	Content string           `json:"content,omitempty"`
+	Design  string           `json:"design,omitempty"`
+	Blocks  map[string]Asset `json:"blocks,omitempty"`
+}
+
+// assetFields has the fields of Asset without its JSON methods
+type assetFields Asset
+
+func (a *Asset) UnmarshalJSON(data []byte) error {
+	var fields assetFields
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+	*a = Asset(fields)
+	a.Raw = append(json.RawMessage(nil), data...)
+	return nil
+}
+
+// MarshalJSON writes Raw, the asset as SFMC returned it, with the fields the fetcher adds:
+// the business unit, the category path and the composition. Typed fields are only written
+// for assets without Raw, leaving out the zero dates, type and category SFMC never sent.
+func (a Asset) MarshalJSON() ([]byte, error) {
+	if len(a.Raw) == 0 {
+		return a.marshalTyped()
+	}
+
+	var merged map[string]json.RawMessage
+	if err := json.Unmarshal(a.Raw, &merged); err != nil {
+		return nil, err
+	}
+	if a.BusinessUnitID != 0 {
+		if err := setJSON(merged, "businessUnitId", a.BusinessUnitID); err != nil {
+			return nil, err
+		}
+	}
+	if a.Category.Path != "" {
+		category := map[string]json.RawMessage{}
+		if raw, found := merged["category"]; found && string(raw) != "null" {
+			if err := json.Unmarshal(raw, &category); err != nil {
+				return nil, err
+			}
+		}
+		if err := setJSON(category, "path", a.Category.Path); err != nil {
+			return nil, err
+		}
+		if err := setJSON(merged, "category", category); err != nil {
+			return nil, err
+		}
+	}
+	if a.Composition != nil {
+		if err := setJSON(merged, "composition", a.Composition); err != nil {
+			return nil, err
+		}
+	}
+
+	return json.Marshal(merged)
+}
+
+// marshalTyped writes the typed fields of an asset built without Raw
+func (a Asset) marshalTyped() ([]byte, error) {
+	typed, err := json.Marshal(assetFields(a))
+	if err != nil {
+		return nil, err
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(typed, &fields); err != nil {
+		return nil, err
+	}
+	if a.CreatedDate.IsZero() {
+		delete(fields, "createdDate")
+	}
+	if a.ModifiedDate.IsZero() {
+		delete(fields, "modifiedDate")
+	}
+	if a.AssetType == (AssetType{}) {
+		delete(fields, "assetType")
+	}
+	if a.Category == (Category{}) {
+		delete(fields, "category")
+	}
+
+	return json.Marshal(fields)
+}
+
+func setJSON(fields map[string]json.RawMessage, key string, value any) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	fields[key] = data
+	return nil
+}
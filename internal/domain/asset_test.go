@@ -0,0 +1,100 @@
+package domain
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAsset_JSON(t *testing.T) {
+	raw := `{
+		"id": 1234,
+		"customerKey": "footer-key",
+		"name": "Footer",
+		"assetType": {"id": 197, "name": "htmlblock", "displayName": "HTML Block"},
+		"category": {"id": 42, "name": "Footers", "parentId": 7},
+		"version": 3,
+		"owner": {"id": 5, "email": "owner@example.com", "name": "Owner"},
+		"createdDate": "2024-01-02T03:04:05.123-06:00",
+		"modifiedDate": "2024-02-03T04:05:06.789-06:00",
+		"content": "<p>footer</p>",
+		"views": {"html": {"content": "<p>footer</p>", "slots": {"main": {"blocks": {"abc": {"id": 99, "assetType": {"id": 197}}}}}}},
+		"sharingProperties": {"sharingType": "local"}
+	}`
+
+	var asset Asset
+	require.NoError(t, json.Unmarshal([]byte(raw), &asset))
+
+	require.Equal(t, 1234, asset.ID)
+	require.Equal(t, "footer-key", asset.CustomerKey)
+	require.Equal(t, "htmlblock", asset.AssetType.Name)
+	require.Equal(t, 42, asset.Category.ID)
+	require.Equal(t, "owner@example.com", asset.Owner.Email)
+	require.True(t, asset.ModifiedDate.Equal(time.Date(2024, 2, 3, 10, 5, 6, 789000000, time.UTC)))
+	require.Equal(t, 99, asset.Views["html"].Slots["main"].Blocks["abc"].ID)
+	require.JSONEq(t, raw, string(asset.Raw))
+
+	// the fields added by the fetcher are written over the raw JSON, the rest is left as received
+	asset.Name = "Renamed footer"
+	asset.BusinessUnitID = 100
+	asset.Category.Path = "Content Builder/Footers"
+	asset.Composition = &Composition{BlockIDs: []int{99}}
+	data, err := json.Marshal(asset)
+	require.NoError(t, err)
+
+	var decoded map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	require.Equal(t, "Footer", decoded["name"])
+	require.Equal(t, float64(100), decoded["businessUnitId"])
+	require.Equal(t, map[string]interface{}{
+		"id": float64(42), "name": "Footers", "parentId": float64(7), "path": "Content Builder/Footers",
+	}, decoded["category"])
+	require.Equal(t, map[string]interface{}{"blockIds": []interface{}{float64(99)}}, decoded["composition"])
+	require.Equal(t, map[string]interface{}{"sharingType": "local"}, decoded["sharingProperties"])
+}
+
+func TestAsset_JSONUnknownNestedFields(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  string
+	}{
+		{
+			name: "Email with unknown view and file properties",
+			raw: `{
+				"id": 1,
+				"assetType": {"id": 207, "name": "templatebasedemail"},
+				"views": {"html": {
+					"content": "<p>email</p>",
+					"meta": {"options": {"generateFrom": "html"}},
+					"template": {"id": 5, "assetType": {"id": 4}},
+					"slots": {"main": {"content": "<div></div>", "locked": true}}
+				}},
+				"fileProperties": {"fileName": "a.png", "width": 640, "height": 480, "fileCreatedDate": "2024-01-02T03:04:05"}
+			}`,
+		},
+		{
+			name: "Projected asset with a few fields",
+			raw:  `{"id": 2, "customerKey": "footer-key", "modifiedDate": "2024-02-03T04:05:06.789-06:00"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var asset Asset
+			require.NoError(t, json.Unmarshal([]byte(tt.raw), &asset))
+
+			// nothing is lost and nothing SFMC did not send is added
+			data, err := json.Marshal(asset)
+			require.NoError(t, err)
+			require.JSONEq(t, tt.raw, string(data))
+		})
+	}
+}
+
+func TestAsset_JSONWithoutRaw(t *testing.T) {
+	data, err := json.Marshal(Asset{ID: 1, Content: "Block 1"})
+	require.NoError(t, err)
+	require.JSONEq(t, `{"id":1,"content":"Block 1"}`, string(data))
+}
@@ -0,0 +1,96 @@
+package domain
+
+import (
+	"maps"
+	"slices"
+)
+
+// Composition is the slot tree of an email or template,
+// showing which content blocks are embedded where
+type Composition struct {
+	// Slots are the slots of the asset itself, templates define them at the top level
+	Slots []SlotComposition `json:"slots,omitempty"`
+	// Views are the slot trees of the views of an email e.g. html
+	Views map[string][]SlotComposition `json:"views,omitempty"`
+	// BlockIDs are the distinct IDs of every block embedded at any depth, sorted
+	BlockIDs []int `json:"blockIds"`
+}
+
+type SlotComposition struct {
+	Key    string           `json:"key"`
+	Blocks []BlockReference `json:"blocks,omitempty"`
+}
+
+// BlockReference is a block embedded in a slot,
+// layout blocks have slots of their own holding further blocks
+type BlockReference struct {
+	// Key is the key of the block within its slot
+	Key         string            `json:"key"`
+	ID          int               `json:"id,omitempty"`
+	CustomerKey string            `json:"customerKey,omitempty"`
+	Name        string            `json:"name,omitempty"`
+	AssetType   string            `json:"assetType,omitempty"`
+	Slots       []SlotComposition `json:"slots,omitempty"`
+}
+
+// ResolveComposition walks the slots of an asset and its views,
+// it returns nil when the asset has no slots at all
+func ResolveComposition(asset Asset) *Composition {
+	composition := &Composition{
+		Slots:    composeSlots(asset.Slots),
+		BlockIDs: []int{},
+	}
+	for _, key := range slices.Sorted(maps.Keys(asset.Views)) {
+		if slots := composeSlots(asset.Views[key].Slots); len(slots) > 0 {
+			if composition.Views == nil {
+				composition.Views = map[string][]SlotComposition{}
+			}
+			composition.Views[key] = slots
+		}
+	}
+	if len(composition.Slots) == 0 && len(composition.Views) == 0 {
+		return nil
+	}
+
+	blockIDs := map[int]bool{}
+	collectBlockIDs(composition.Slots, blockIDs)
+	for _, slots := range composition.Views {
+		collectBlockIDs(slots, blockIDs)
+	}
+	composition.BlockIDs = append(composition.BlockIDs, slices.Sorted(maps.Keys(blockIDs))...)
+
+	return composition
+}
+
+// composeSlots orders slots and blocks by key as SFMC returns them as JSON objects
+func composeSlots(slots map[string]Slot) []SlotComposition {
+	var composed []SlotComposition
+	for _, slotKey := range slices.Sorted(maps.Keys(slots)) {
+		slot := SlotComposition{Key: slotKey}
+		blocks := slots[slotKey].Blocks
+		for _, blockKey := range slices.Sorted(maps.Keys(blocks)) {
+			block := blocks[blockKey]
+			slot.Blocks = append(slot.Blocks, BlockReference{
+				Key:         blockKey,
+				ID:          block.ID,
+				CustomerKey: block.CustomerKey,
+				Name:        block.Name,
+				AssetType:   block.AssetType.Name,
+				Slots:       composeSlots(block.Slots),
+			})
+		}
+		composed = append(composed, slot)
+	}
+	return composed
+}
+
+func collectBlockIDs(slots []SlotComposition, blockIDs map[int]bool) {
+	for _, slot := range slots {
+		for _, block := range slot.Blocks {
+			if block.ID != 0 {
+				blockIDs[block.ID] = true
+			}
+			collectBlockIDs(block.Slots, blockIDs)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package domain
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveComposition(t *testing.T) {
+	tests := []struct {
+		name  string
+		asset string
+		want  *Composition
+	}{
+		{
+			name: "Email with nested layout block",
+			asset: `{
+				"id": 1,
+				"assetType": {"id": 207, "name": "templatebasedemail"},
+				"views": {
+					"html": {
+						"slots": {
+							"main": {
+								"blocks": {
+									"b2": {"id": 20, "customerKey": "footer", "name": "Footer", "assetType": {"name": "htmlblock"}},
+									"b1": {
+										"id": 10,
+										"assetType": {"name": "layoutblock"},
+										"slots": {
+											"col1": {"blocks": {"x": {"id": 30, "assetType": {"name": "textblock"}}}},
+											"col2": {"blocks": {"y": {"id": 20, "assetType": {"name": "htmlblock"}}}}
+										}
+									}
+								}
+							}
+						}
+					},
+					"subjectline": {"content": "Hello"}
+				}
+			}`,
+			want: &Composition{
+				Views: map[string][]SlotComposition{
+					"html": {{
+						Key: "main",
+						Blocks: []BlockReference{
+							{
+								Key:       "b1",
+								ID:        10,
+								AssetType: "layoutblock",
+								Slots: []SlotComposition{
+									{Key: "col1", Blocks: []BlockReference{{Key: "x", ID: 30, AssetType: "textblock"}}},
+									{Key: "col2", Blocks: []BlockReference{{Key: "y", ID: 20, AssetType: "htmlblock"}}},
+								},
+							},
+							{Key: "b2", ID: 20, CustomerKey: "footer", Name: "Footer", AssetType: "htmlblock"},
+						},
+					}},
+				},
+				BlockIDs: []int{10, 20, 30},
+			},
+		},
+		{
+			name:  "Template with empty slot",
+			asset: `{"id": 2, "assetType": {"name": "template"}, "slots": {"banner": {"content": "<div></div>"}}}`,
+			want: &Composition{
+				Slots:    []SlotComposition{{Key: "banner"}},
+				BlockIDs: []int{},
+			},
+		},
+		{
+			name:  "Asset without slots",
+			asset: `{"id": 3, "assetType": {"name": "htmlemail"}, "views": {"html": {"content": "<p></p>"}}}`,
+			want:  nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var asset Asset
+			require.NoError(t, json.Unmarshal([]byte(tt.asset), &asset))
+
+			require.Equal(t, tt.want, ResolveComposition(asset))
+		})
+	}
+}
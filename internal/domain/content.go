@@ -1,31 +1,112 @@
 package domain
 
+import (
+	"errors"
+	"fmt"
+)
+
 type ContentBlocksRequest struct {
-	Page struct {
-		Page     int `json:"page"`
-		PageSize int `json:"pageSize"`
-	} `json:"page"`
-	// directly using struct here as for now not using this field
-	Query struct {
-		LeftOperand struct {
-			Property       string      `json:"property"`
-			SimpleOperator string      `json:"simpleOperator"`
-			Value          interface{} `json:"value"`
-		} `json:"leftOperand"`
-		LogicalOperator string `json:"logicalOperator"`
-		RightOperand    struct {
-			Property       string      `json:"property"`
-			SimpleOperator string      `json:"simpleOperator"`
-			Value          interface{} `json:"value"`
-		} `json:"rightOperand"`
-	} `json:"query"`
-	Sort []struct {
-		Property  string `json:"property"`
-		Direction string `json:"direction"`
-	} `json:"sort"`
-	Fields []string `json:"fields"`
-}
-
-type ContentBlock struct {
-	Content string `json:"content"`
+	Page   Page     `json:"page"`
+	Query  *Query   `json:"query,omitempty"`
+	Sort   []Sort   `json:"sort,omitempty"`
+	Fields []string `json:"fields,omitempty"`
+}
+
+type Page struct {
+	Page     int `json:"page"`
+	PageSize int `json:"pageSize"`
+}
+
+// SortDirection orders the results of a query
+type SortDirection string
+
+const (
+	SortAsc  SortDirection = "ASC"
+	SortDesc SortDirection = "DESC"
+)
+
+type Sort struct {
+	Property  string        `json:"property"`
+	Direction SortDirection `json:"direction"`
+}
+
+// Validate checks the request before it is sent to the fetcher's API
+func (r ContentBlocksRequest) Validate() error {
+	if r.Page.Page < 0 || r.Page.PageSize < 0 {
+		return fmt.Errorf("invalid page %d or page size %d", r.Page.Page, r.Page.PageSize)
+	}
+
+	if r.Query != nil {
+		if err := r.Query.Validate(); err != nil {
+			return fmt.Errorf("invalid query: %w", err)
+		}
+	}
+
+	for _, sort := range r.Sort {
+		if sort.Property == "" {
+			return errors.New("invalid sort: missing property")
+		}
+		if sort.Direction != SortAsc && sort.Direction != SortDesc {
+			return fmt.Errorf("invalid sort: unknown direction %q for property %s", sort.Direction, sort.Property)
+		}
+	}
+
+	for _, field := range r.Fields {
+		if field == "" {
+			return errors.New("invalid fields: empty field name")
+		}
+	}
+
+	return nil
+}
+
+// ContentBlocksRequestBuilder builds a ContentBlocksRequest fluently e.g.
+//
+//	request, err := domain.NewContentBlocksRequest().
+//		Where(domain.And(domain.Equal("assetType.name", "htmlblock"), domain.ModifiedAfterQuery(lastRun))).
+//		SortBy("modifiedDate", domain.SortDesc).
+//		Fields("id", "customerKey", "content").
+//		PageSize(50).
+//		Build()
+type ContentBlocksRequestBuilder struct {
+	request ContentBlocksRequest
+}
+
+func NewContentBlocksRequest() *ContentBlocksRequestBuilder {
+	return &ContentBlocksRequestBuilder{}
+}
+
+// Where sets the query, replacing any previous one
+func (b *ContentBlocksRequestBuilder) Where(query *Query) *ContentBlocksRequestBuilder {
+	b.request.Query = query
+	return b
+}
+
+// AndWhere narrows the current query with another one
+func (b *ContentBlocksRequestBuilder) AndWhere(query *Query) *ContentBlocksRequestBuilder {
+	b.request.Query = And(b.request.Query, query)
+	return b
+}
+
+func (b *ContentBlocksRequestBuilder) SortBy(property string, direction SortDirection) *ContentBlocksRequestBuilder {
+	b.request.Sort = append(b.request.Sort, Sort{Property: property, Direction: direction})
+	return b
+}
+
+func (b *ContentBlocksRequestBuilder) Fields(fields ...string) *ContentBlocksRequestBuilder {
+	b.request.Fields = append(b.request.Fields, fields...)
+	return b
+}
+
+func (b *ContentBlocksRequestBuilder) PageSize(pageSize int) *ContentBlocksRequestBuilder {
+	b.request.Page.PageSize = pageSize
+	return b
+}
+
+// Build validates and returns the request
+func (b *ContentBlocksRequestBuilder) Build() (ContentBlocksRequest, error) {
+	if err := b.request.Validate(); err != nil {
+		return ContentBlocksRequest{}, err
+	}
+	return b.request, nil
 }
@@ -0,0 +1,300 @@
+package domain
+
+import (
+	"cmp"
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// ContentBlockFunction is an AMPscript function including another content block
+type ContentBlockFunction string
+
+const (
+	ContentBlockByKey  ContentBlockFunction = "ContentBlockByKey"
+	ContentBlockByID   ContentBlockFunction = "ContentBlockById"
+	ContentBlockByName ContentBlockFunction = "ContentBlockByName"
+)
+
+// contentBlockCall matches a content block function called with a literal first argument,
+// AMPscript is case insensitive and quotes strings with double or single quotes
+var contentBlockCall = regexp.MustCompile(
+	`(?i)\bContentBlockBy(Key|Id|Name)\s*\(\s*(?:"([^"]*)"|'([^']*)'|(\d+))`,
+)
+
+// ContentBlockReference is a content block included by AMPscript
+type ContentBlockReference struct {
+	Function ContentBlockFunction `json:"function"`
+	// Argument is the key, ID or name as written, names are Content Builder paths
+	// separated by backslashes e.g. "Content Builder\Newsletters\Footer"
+	Argument string `json:"argument"`
+}
+
+// ParseContentBlockReferences finds the content blocks included by AMPscript, in order and without duplicates.
+// Calls with a variable as argument cannot be resolved before the send and are left out.
+func ParseContentBlockReferences(content string) []ContentBlockReference {
+	var references []ContentBlockReference
+	for _, match := range contentBlockCall.FindAllStringSubmatch(content, -1) {
+		reference := ContentBlockReference{Argument: match[2] + match[3] + match[4]}
+		switch strings.ToLower(match[1]) {
+		case "key":
+			reference.Function = ContentBlockByKey
+		case "id":
+			reference.Function = ContentBlockByID
+		default:
+			reference.Function = ContentBlockByName
+		}
+		if !slices.Contains(references, reference) {
+			references = append(references, reference)
+		}
+	}
+	return references
+}
+
+// DependencyNode is an asset of the dependency graph with the content blocks it includes
+type DependencyNode struct {
+	ID             int    `json:"id"`
+	CustomerKey    string `json:"customerKey,omitempty"`
+	Name           string `json:"name,omitempty"`
+	CategoryPath   string `json:"categoryPath,omitempty"`
+	BusinessUnitID int    `json:"businessUnitId,omitempty"`
+
+	References []ContentBlockReference `json:"-"`
+}
+
+// NewDependencyNode parses the content of an asset, its views and the blocks embedded in its slots
+func NewDependencyNode(asset Asset) DependencyNode {
+	var references []ContentBlockReference
+	for _, content := range assetContents(asset) {
+		for _, reference := range ParseContentBlockReferences(content) {
+			if !slices.Contains(references, reference) {
+				references = append(references, reference)
+			}
+		}
+	}
+
+	return DependencyNode{
+		ID:             asset.ID,
+		CustomerKey:    asset.CustomerKey,
+		Name:           asset.Name,
+		CategoryPath:   asset.Category.Path,
+		BusinessUnitID: asset.BusinessUnitID,
+		References:     references,
+	}
+}
+
+// assetContents lists every content of an asset in a stable order
+func assetContents(asset Asset) []string {
+	contents := []string{asset.Content}
+	contents = append(contents, slotContents(asset.Slots)...)
+	for _, key := range slices.Sorted(maps.Keys(asset.Views)) {
+		view := asset.Views[key]
+		contents = append(contents, view.Content)
+		contents = append(contents, slotContents(view.Slots)...)
+	}
+	return contents
+}
+
+func slotContents(slots map[string]Slot) []string {
+	var contents []string
+	for _, slotKey := range slices.Sorted(maps.Keys(slots)) {
+		slot := slots[slotKey]
+		contents = append(contents, slot.Content)
+		for _, blockKey := range slices.Sorted(maps.Keys(slot.Blocks)) {
+			contents = append(contents, assetContents(slot.Blocks[blockKey])...)
+		}
+	}
+	return contents
+}
+
+// DependencyEdge is a content block included by an asset, To is zero for a dangling reference.
+// Both assets belong to the same business unit.
+type DependencyEdge struct {
+	BusinessUnitID int                   `json:"businessUnitId,omitempty"`
+	From           int                   `json:"from"`
+	To             int                   `json:"to,omitempty"`
+	Reference      ContentBlockReference `json:"reference"`
+}
+
+// DependencyCycle is a group of assets of a business unit including each other
+type DependencyCycle struct {
+	BusinessUnitID int   `json:"businessUnitId,omitempty"`
+	AssetIDs       []int `json:"assetIds"`
+}
+
+// DependencyGraph tells which content blocks every asset of a snapshot includes
+type DependencyGraph struct {
+	Nodes []DependencyNode `json:"nodes"`
+	Edges []DependencyEdge `json:"edges"`
+	// Dangling are the references to content blocks missing from the snapshot
+	Dangling []DependencyEdge  `json:"dangling"`
+	Cycles   []DependencyCycle `json:"cycles"`
+}
+
+// BuildDependencyGraph resolves the references of the nodes against each other.
+// References resolve within the business unit of the asset, names against the category path.
+func BuildDependencyGraph(nodes []DependencyNode) DependencyGraph {
+	graph := DependencyGraph{
+		Nodes:    slices.Clone(nodes),
+		Edges:    []DependencyEdge{},
+		Dangling: []DependencyEdge{},
+		Cycles:   []DependencyCycle{},
+	}
+	slices.SortFunc(graph.Nodes, func(a, b DependencyNode) int {
+		return cmp.Or(cmp.Compare(a.BusinessUnitID, b.BusinessUnitID), cmp.Compare(a.ID, b.ID))
+	})
+
+	index := newDependencyIndex(graph.Nodes)
+	for _, node := range graph.Nodes {
+		for _, reference := range node.References {
+			edge := DependencyEdge{BusinessUnitID: node.BusinessUnitID, From: node.ID, Reference: reference}
+			if to, found := index.resolve(node.BusinessUnitID, reference); found {
+				edge.To = to
+				graph.Edges = append(graph.Edges, edge)
+			} else {
+				graph.Dangling = append(graph.Dangling, edge)
+			}
+		}
+	}
+
+	graph.Cycles = findCycles(graph.Nodes, graph.Edges)
+	return graph
+}
+
+// dependencyIndex finds the asset IDs by key, ID and path, per business unit
+type dependencyIndex map[string]int
+
+func newDependencyIndex(nodes []DependencyNode) dependencyIndex {
+	index := dependencyIndex{}
+	for _, node := range nodes {
+		index[index.key(node.BusinessUnitID, ContentBlockByID, strconv.Itoa(node.ID))] = node.ID
+		if node.CustomerKey != "" {
+			index[index.key(node.BusinessUnitID, ContentBlockByKey, node.CustomerKey)] = node.ID
+		}
+		index[index.key(node.BusinessUnitID, ContentBlockByName, node.CategoryPath+"/"+node.Name)] = node.ID
+	}
+	return index
+}
+
+func (dependencyIndex) key(businessUnitID int, function ContentBlockFunction, argument string) string {
+	// keys and names are case insensitive, names are paths separated
+	// by backslashes while category paths are separated by slashes
+	switch function {
+	case ContentBlockByName:
+		argument = strings.ToLower(strings.ReplaceAll(argument, `\`, "/"))
+	case ContentBlockByKey:
+		argument = strings.ToLower(argument)
+	}
+	return fmt.Sprintf("%d:%s:%s", businessUnitID, function, argument)
+}
+
+func (i dependencyIndex) resolve(businessUnitID int, reference ContentBlockReference) (int, bool) {
+	id, found := i[i.key(businessUnitID, reference.Function, reference.Argument)]
+	return id, found
+}
+
+// dependencyNodeID identifies an asset of the graph, IDs repeat across business units
+type dependencyNodeID struct {
+	businessUnitID int
+	id             int
+}
+
+// findCycles returns the strongly connected components of the graph with more than
+// one asset or an asset including itself, in order of business unit and first asset
+func findCycles(nodes []DependencyNode, edges []DependencyEdge) []DependencyCycle {
+	successors := map[dependencyNodeID][]dependencyNodeID{}
+	for _, edge := range edges {
+		from := dependencyNodeID{edge.BusinessUnitID, edge.From}
+		successors[from] = append(successors[from], dependencyNodeID{edge.BusinessUnitID, edge.To})
+	}
+
+	// Tarjan's algorithm
+	indexes := map[dependencyNodeID]int{}
+	lowLinks := map[dependencyNodeID]int{}
+	onStack := map[dependencyNodeID]bool{}
+	var stack []dependencyNodeID
+	cycles := []DependencyCycle{}
+
+	var connect func(node dependencyNodeID)
+	connect = func(node dependencyNodeID) {
+		indexes[node] = len(indexes)
+		lowLinks[node] = indexes[node]
+		stack = append(stack, node)
+		onStack[node] = true
+
+		for _, successor := range successors[node] {
+			if _, visited := indexes[successor]; !visited {
+				connect(successor)
+				lowLinks[node] = min(lowLinks[node], lowLinks[successor])
+			} else if onStack[successor] {
+				lowLinks[node] = min(lowLinks[node], indexes[successor])
+			}
+		}
+
+		if lowLinks[node] != indexes[node] {
+			return
+		}
+		var component []int
+		for {
+			last := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			onStack[last] = false
+			component = append(component, last.id)
+			if last == node {
+				break
+			}
+		}
+		if len(component) > 1 || slices.Contains(successors[node], node) {
+			slices.Sort(component)
+			cycles = append(cycles, DependencyCycle{BusinessUnitID: node.businessUnitID, AssetIDs: component})
+		}
+	}
+
+	for _, node := range nodes {
+		id := dependencyNodeID{node.BusinessUnitID, node.ID}
+		if _, visited := indexes[id]; !visited {
+			connect(id)
+		}
+	}
+
+	slices.SortFunc(cycles, func(a, b DependencyCycle) int {
+		return cmp.Or(cmp.Compare(a.BusinessUnitID, b.BusinessUnitID), cmp.Compare(a.AssetIDs[0], b.AssetIDs[0]))
+	})
+	return cycles
+}
+
+// DOT renders the graph in the Graphviz DOT language, dangling references
+// are drawn as red dashed edges to a node named after the reference
+func (g DependencyGraph) DOT() string {
+	var builder strings.Builder
+	builder.WriteString("digraph dependencies {\n")
+	for _, node := range g.Nodes {
+		fmt.Fprintf(&builder, "  %s [label=%s];\n", dotNodeID(node.BusinessUnitID, node.ID), strconv.Quote(node.Name))
+	}
+	for _, edge := range g.Edges {
+		fmt.Fprintf(
+			&builder,
+			"  %s -> %s;\n",
+			dotNodeID(edge.BusinessUnitID, edge.From),
+			dotNodeID(edge.BusinessUnitID, edge.To),
+		)
+	}
+	for _, edge := range g.Dangling {
+		missing := strconv.Quote(fmt.Sprintf("%s(%s)", edge.Reference.Function, edge.Reference.Argument))
+		fmt.Fprintf(&builder, "  %s [shape=box, color=red];\n", missing)
+		fmt.Fprintf(&builder, "  %s -> %s [style=dashed, color=red];\n", dotNodeID(edge.BusinessUnitID, edge.From), missing)
+	}
+	builder.WriteString("}\n")
+	return builder.String()
+}
+
+// dotNodeID names an asset in DOT, prefixed with its business unit unless the default one
+func dotNodeID(businessUnitID, id int) string {
+	if businessUnitID == 0 {
+		return strconv.Quote(strconv.Itoa(id))
+	}
+	return strconv.Quote(fmt.Sprintf("%d/%d", businessUnitID, id))
+}
@@ -0,0 +1,70 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseContentBlockReferences(t *testing.T) {
+	content := `%%[ SET @footer = ContentBlockByKey("footer") ]%%
+		%%=contentblockbyid(1234)=%% %%=ContentBlockById("1234")=%%
+		%%=ContentBlockByName('Content Builder\Shared\Disclaimer')=%%
+		%%=ContentBlockByKey(@dynamicKey)=%% %%=ContentBlockByKey("footer")=%%`
+
+	require.Equal(t, []ContentBlockReference{
+		{Function: ContentBlockByKey, Argument: "footer"},
+		{Function: ContentBlockByID, Argument: "1234"},
+		{Function: ContentBlockByName, Argument: `Content Builder\Shared\Disclaimer`},
+	}, ParseContentBlockReferences(content))
+	require.Empty(t, ParseContentBlockReferences("<p>no AMPscript</p>"))
+}
+
+func TestBuildDependencyGraph(t *testing.T) {
+	shared := Category{ID: 2, Path: "Content Builder/Shared"}
+	email := Asset{ID: 1, Name: "Newsletter", Views: map[string]AssetView{
+		"html": {Slots: map[string]Slot{"main": {Blocks: map[string]Asset{
+			"b1": {Content: `%%=ContentBlockByKey("Footer")=%%`},
+		}}}},
+	}}
+	footer := Asset{
+		ID: 2, CustomerKey: "footer", Name: "Footer", Category: shared,
+		Content: `%%=ContentBlockByName("Content Builder\Shared\Disclaimer")=%%`,
+	}
+	disclaimer := Asset{
+		ID: 3, CustomerKey: "disclaimer", Name: "Disclaimer", Category: shared,
+		Content: `%%=ContentBlockById(2)=%% %%=ContentBlockByKey("archived")=%%`,
+	}
+	// the same ID in another business unit does not resolve references of the first one
+	loop := Asset{ID: 2, Name: "Loop", BusinessUnitID: 100, Content: `%%=ContentBlockById(2)=%%`}
+
+	var nodes []DependencyNode
+	for _, asset := range []Asset{loop, disclaimer, footer, email} {
+		nodes = append(nodes, NewDependencyNode(asset))
+	}
+	graph := BuildDependencyGraph(nodes)
+
+	var nodeIDs []int
+	for _, node := range graph.Nodes {
+		nodeIDs = append(nodeIDs, node.ID)
+	}
+	require.Equal(t, []int{1, 2, 3, 2}, nodeIDs)
+	require.Equal(t, []DependencyEdge{
+		{From: 1, To: 2, Reference: ContentBlockReference{ContentBlockByKey, "Footer"}},
+		{From: 2, To: 3, Reference: ContentBlockReference{ContentBlockByName, `Content Builder\Shared\Disclaimer`}},
+		{From: 3, To: 2, Reference: ContentBlockReference{ContentBlockByID, "2"}},
+		{BusinessUnitID: 100, From: 2, To: 2, Reference: ContentBlockReference{ContentBlockByID, "2"}},
+	}, graph.Edges)
+	require.Equal(t, []DependencyEdge{
+		{From: 3, Reference: ContentBlockReference{ContentBlockByKey, "archived"}},
+	}, graph.Dangling)
+	require.Equal(t, []DependencyCycle{
+		{AssetIDs: []int{2, 3}},
+		{BusinessUnitID: 100, AssetIDs: []int{2}},
+	}, graph.Cycles)
+
+	dot := graph.DOT()
+	require.Contains(t, dot, `"1" -> "2";`)
+	require.Contains(t, dot, `"100/2" -> "100/2";`)
+	require.Contains(t, dot, `"3" -> "ContentBlockByKey(archived)" [style=dashed, color=red];`)
+}
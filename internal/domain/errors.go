@@ -0,0 +1,76 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrAssetNotFound is returned when a single asset asked for does not exist
+var ErrAssetNotFound = errors.New("asset not found")
+
+// PageError describes a single page that could not be fetched
+type PageError struct {
+	// BusinessUnitID is the MID the page was fetched from, zero for the default business unit
+	BusinessUnitID int
+	Page           int
+	Err            error
+}
+
+func (e PageError) Error() string {
+	if e.BusinessUnitID != 0 {
+		return fmt.Sprintf("business unit %d page %d: %v", e.BusinessUnitID, e.Page, e.Err)
+	}
+	return fmt.Sprintf("page %d: %v", e.Page, e.Err)
+}
+
+func (e PageError) Unwrap() error {
+	return e.Err
+}
+
+// PartialResultError reports the pages missing from a fetch result.
+// A fetcher returns it alongside the content blocks when its policy accepted
+// the incomplete result, and wrapped without content blocks when it did not.
+type PartialResultError struct {
+	TotalPages  int
+	FailedPages []PageError
+	// Accepted is set when the fetched content blocks are usable although incomplete
+	Accepted bool
+}
+
+func (e *PartialResultError) Error() string {
+	failures := make([]string, 0, len(e.FailedPages))
+	for _, pageErr := range e.FailedPages {
+		failures = append(failures, pageErr.Error())
+	}
+	return fmt.Sprintf(
+		"%d of %d pages failed: %s",
+		len(e.FailedPages),
+		e.TotalPages,
+		strings.Join(failures, "; "),
+	)
+}
+
+func (e *PartialResultError) Unwrap() []error {
+	errs := make([]error, 0, len(e.FailedPages))
+	for _, pageErr := range e.FailedPages {
+		errs = append(errs, pageErr)
+	}
+	return errs
+}
+
+// FailedPageNumbers lists the page numbers that could not be fetched
+func (e *PartialResultError) FailedPageNumbers() []int {
+	pages := make([]int, 0, len(e.FailedPages))
+	for _, pageErr := range e.FailedPages {
+		pages = append(pages, pageErr.Page)
+	}
+	return pages
+}
+
+// IsPartialResult reports whether err is a PartialResultError accepted by the fetcher,
+// i.e. the content blocks returned with it are usable although incomplete
+func IsPartialResult(err error) bool {
+	var partialErr *PartialResultError
+	return errors.As(err, &partialErr) && partialErr.Accepted
+}
@@ -1,13 +1,108 @@
 package domain
 
-import "context"
+import (
+	"context"
+	"io"
+	"iter"
+	"time"
+)
 
 // Uploader uploads content blocks to implemented uploader e.g. local, s3_client
 type Uploader interface {
-	UploadContentBlocks(ctx context.Context, contentBlocks []ContentBlock) error
+	UploadContentBlocks(ctx context.Context, contentBlocks []Asset) error
 }
 
 // Fetcher fetches content blocks to implemented e.g. salesforce (as of now)
 type Fetcher interface {
-	FetchContentBlocks(ctx context.Context, request ContentBlocksRequest) ([]ContentBlock, error)
+	FetchContentBlocks(ctx context.Context, request ContentBlocksRequest) ([]Asset, error)
+}
+
+// StreamingFetcher is implemented by fetchers able to hand over content blocks while
+// they are fetched instead of collecting the whole catalog in memory.
+// An error ends the sequence, it is a PartialResultError when some pages are missing.
+type StreamingFetcher interface {
+	StreamContentBlocks(ctx context.Context, request ContentBlocksRequest) iter.Seq2[Asset, error]
+}
+
+// StreamingUploader is implemented by uploaders able to store content blocks while
+// consuming them from a StreamingFetcher. An accepted partial result is stored and its
+// error returned, any other error from the sequence discards the upload.
+type StreamingUploader interface {
+	UploadContentBlockStream(ctx context.Context, contentBlocks iter.Seq2[Asset, error]) error
+}
+
+// AssetGetter is implemented by fetchers able to fetch a single asset,
+// ErrAssetNotFound is returned when it does not exist
+type AssetGetter interface {
+	GetAsset(ctx context.Context, id int) (Asset, error)
+	GetAssetByKey(ctx context.Context, customerKey string) (Asset, error)
+}
+
+// AssetUploader is implemented by uploaders able to store a single asset
+// without replacing the content blocks snapshot
+type AssetUploader interface {
+	UploadAsset(ctx context.Context, asset Asset) error
+}
+
+// AssetFileFetcher is implemented by fetchers able to download the file of a binary asset.
+// The caller must close the returned reader.
+type AssetFileFetcher interface {
+	OpenAssetFile(ctx context.Context, asset Asset) (io.ReadCloser, error)
+}
+
+// SizedReader is implemented by the readers of AssetFileFetcher when the download itself
+// tells the size of the file in bytes, Size is negative when it does not.
+// The file size in the asset metadata may be outdated and is not to be relied on.
+type SizedReader interface {
+	io.Reader
+	Size() int64
+}
+
+// AssetFileUploader is implemented by uploaders able to store the file of a binary asset
+// next to the content blocks, reading it as a stream
+type AssetFileUploader interface {
+	UploadAssetFile(ctx context.Context, asset Asset, file io.Reader) error
+}
+
+// SnapshotManifestStore is implemented by uploaders able to store next to the content blocks
+// snapshot whether it is complete, so a partial snapshot is told apart from a full one
+type SnapshotManifestStore interface {
+	UploadSnapshotManifest(ctx context.Context, manifest SnapshotManifest) error
+}
+
+// RunTracker is implemented by fetchers and uploaders accounting their calls per run,
+// e.g. against an API call budget. StartRun is called once when a run begins,
+// every call until the next run counts against the same budget.
+type RunTracker interface {
+	StartRun()
+}
+
+// WatermarkStore persists the high-water mark of the last successful sync e.g. local, s3_client
+// GetWatermark returns the zero time when no watermark has been stored yet
+type WatermarkStore interface {
+	GetWatermark(ctx context.Context) (time.Time, error)
+	SaveWatermark(ctx context.Context, watermark time.Time) error
+}
+
+// TokenStore shares access tokens between runs and processes e.g. memory, local, s3_client
+// GetToken returns the zero AccessToken when no token is stored under the key
+type TokenStore interface {
+	GetToken(ctx context.Context, key string) (AccessToken, error)
+	SaveToken(ctx context.Context, key string, token AccessToken) error
+	DeleteToken(ctx context.Context, key string) error
+}
+
+// TombstoneStore is implemented by uploaders able to remember the assets of the last run
+// and to store tombstones of the assets deleted since.
+// GetAssetIndex returns nil when no index has been stored yet.
+type TombstoneStore interface {
+	GetAssetIndex(ctx context.Context) ([]IndexedAsset, error)
+	SaveAssetIndex(ctx context.Context, index []IndexedAsset) error
+	UploadTombstones(ctx context.Context, tombstones []Tombstone) error
+}
+
+// DependencyGraphStore is implemented by uploaders able to store the dependency graph
+// of the content blocks next to their snapshot
+type DependencyGraphStore interface {
+	UploadDependencyGraph(ctx context.Context, graph DependencyGraph) error
 }
@@ -0,0 +1,242 @@
+package domain
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// SimpleOperator compares an asset property with a value in a simple query
+type SimpleOperator string
+
+const (
+	OperatorEqual              SimpleOperator = "equal"
+	OperatorNotEqual           SimpleOperator = "notEqual"
+	OperatorLessThan           SimpleOperator = "lessThan"
+	OperatorLessThanOrEqual    SimpleOperator = "lessThanOrEqual"
+	OperatorGreaterThan        SimpleOperator = "greaterThan"
+	OperatorGreaterThanOrEqual SimpleOperator = "greaterThanOrEqual"
+	OperatorLike               SimpleOperator = "like"
+	OperatorIsNull             SimpleOperator = "isNull"
+	OperatorIsNotNull          SimpleOperator = "isNotNull"
+	OperatorContains           SimpleOperator = "contains"
+	OperatorMustContain        SimpleOperator = "mustContain"
+	OperatorStartsWith         SimpleOperator = "startsWith"
+	OperatorIn                 SimpleOperator = "in"
+	OperatorWhere              SimpleOperator = "where"
+)
+
+func (o SimpleOperator) valid() bool {
+	switch o {
+	case OperatorEqual, OperatorNotEqual, OperatorLessThan, OperatorLessThanOrEqual,
+		OperatorGreaterThan, OperatorGreaterThanOrEqual, OperatorLike, OperatorIsNull,
+		OperatorIsNotNull, OperatorContains, OperatorMustContain, OperatorStartsWith,
+		OperatorIn, OperatorWhere:
+		return true
+	}
+	return false
+}
+
+// LogicalOperator joins the two operands of a complex query
+type LogicalOperator string
+
+const (
+	LogicalAnd LogicalOperator = "AND"
+	LogicalOr  LogicalOperator = "OR"
+)
+
+// Query is either a simple query (Property, SimpleOperator, Value)
+// or a complex one (LeftOperand, LogicalOperator, RightOperand)
+// whose operands may themselves be complex
+type Query struct {
+	Property        string
+	SimpleOperator  SimpleOperator
+	Value           interface{}
+	LeftOperand     *Query
+	LogicalOperator LogicalOperator
+	RightOperand    *Query
+}
+
+// NewSimpleQuery compares property with value using operator
+func NewSimpleQuery(property string, operator SimpleOperator, value interface{}) *Query {
+	return &Query{
+		Property:       property,
+		SimpleOperator: operator,
+		Value:          value,
+	}
+}
+
+func Equal(property string, value interface{}) *Query {
+	return NewSimpleQuery(property, OperatorEqual, value)
+}
+
+func NotEqual(property string, value interface{}) *Query {
+	return NewSimpleQuery(property, OperatorNotEqual, value)
+}
+
+func LessThan(property string, value interface{}) *Query {
+	return NewSimpleQuery(property, OperatorLessThan, value)
+}
+
+func GreaterThan(property string, value interface{}) *Query {
+	return NewSimpleQuery(property, OperatorGreaterThan, value)
+}
+
+func Like(property string, value string) *Query {
+	return NewSimpleQuery(property, OperatorLike, value)
+}
+
+func StartsWith(property string, value string) *Query {
+	return NewSimpleQuery(property, OperatorStartsWith, value)
+}
+
+func IsNull(property string) *Query {
+	return NewSimpleQuery(property, OperatorIsNull, nil)
+}
+
+func IsNotNull(property string) *Query {
+	return NewSimpleQuery(property, OperatorIsNotNull, nil)
+}
+
+// In matches assets whose property equals any of values
+func In[T any](property string, values ...T) *Query {
+	return NewSimpleQuery(property, OperatorIn, values)
+}
+
+// And joins queries so all of them must match, nil queries are skipped
+func And(queries ...*Query) *Query {
+	return join(LogicalAnd, queries)
+}
+
+// Or joins queries so any of them must match, nil queries are skipped
+func Or(queries ...*Query) *Query {
+	return join(LogicalOr, queries)
+}
+
+// join folds queries into a left-deep tree of complex queries
+// since SFMC only accepts two operands per complex query
+func join(operator LogicalOperator, queries []*Query) *Query {
+	var joined *Query
+	for _, query := range queries {
+		if query == nil {
+			continue
+		}
+		if joined == nil {
+			joined = query
+			continue
+		}
+		joined = &Query{
+			LeftOperand:     joined,
+			LogicalOperator: operator,
+			RightOperand:    query,
+		}
+	}
+	return joined
+}
+
+// ModifiedAfterQuery matches assets modified strictly after the given time
+func ModifiedAfterQuery(since time.Time) *Query {
+	return GreaterThan("modifiedDate", since.Format(time.RFC3339Nano))
+}
+
+func (q *Query) isComplex() bool {
+	return q.LeftOperand != nil || q.RightOperand != nil || q.LogicalOperator != ""
+}
+
+// Validate checks the query tree is one SFMC accepts
+func (q *Query) Validate() error {
+	if q.isComplex() {
+		if q.Property != "" || q.SimpleOperator != "" || q.Value != nil {
+			return errors.New("complex query must not set property, simple operator or value")
+		}
+		if q.LogicalOperator != LogicalAnd && q.LogicalOperator != LogicalOr {
+			return fmt.Errorf("unknown logical operator %q", q.LogicalOperator)
+		}
+		if q.LeftOperand == nil || q.RightOperand == nil {
+			return errors.New("complex query requires both left and right operands")
+		}
+		if err := q.LeftOperand.Validate(); err != nil {
+			return fmt.Errorf("left operand: %w", err)
+		}
+		if err := q.RightOperand.Validate(); err != nil {
+			return fmt.Errorf("right operand: %w", err)
+		}
+		return nil
+	}
+
+	if q.Property == "" {
+		return errors.New("simple query requires a property")
+	}
+	if !q.SimpleOperator.valid() {
+		return fmt.Errorf("unknown simple operator %q for property %s", q.SimpleOperator, q.Property)
+	}
+
+	switch q.SimpleOperator {
+	case OperatorIsNull, OperatorIsNotNull:
+		if q.Value != nil {
+			return fmt.Errorf("operator %s takes no value for property %s", q.SimpleOperator, q.Property)
+		}
+	case OperatorIn:
+		value := reflect.ValueOf(q.Value)
+		if value.Kind() != reflect.Slice || value.Len() == 0 {
+			return fmt.Errorf("operator in requires a non-empty list for property %s", q.Property)
+		}
+	default:
+		if q.Value == nil {
+			return fmt.Errorf("operator %s requires a value for property %s", q.SimpleOperator, q.Property)
+		}
+	}
+
+	return nil
+}
+
+// queryJSON is the wire format of Query, a simple query keeps zero values
+// like false or 0 while a complex one leaves the simple fields out
+type queryJSON struct {
+	Property        string          `json:"property,omitempty"`
+	SimpleOperator  SimpleOperator  `json:"simpleOperator,omitempty"`
+	Value           *interface{}    `json:"value,omitempty"`
+	LeftOperand     *Query          `json:"leftOperand,omitempty"`
+	LogicalOperator LogicalOperator `json:"logicalOperator,omitempty"`
+	RightOperand    *Query          `json:"rightOperand,omitempty"`
+}
+
+func (q Query) MarshalJSON() ([]byte, error) {
+	if q.isComplex() {
+		return json.Marshal(queryJSON{
+			LeftOperand:     q.LeftOperand,
+			LogicalOperator: q.LogicalOperator,
+			RightOperand:    q.RightOperand,
+		})
+	}
+
+	wire := queryJSON{
+		Property:       q.Property,
+		SimpleOperator: q.SimpleOperator,
+	}
+	if q.SimpleOperator != OperatorIsNull && q.SimpleOperator != OperatorIsNotNull {
+		wire.Value = &q.Value
+	}
+	return json.Marshal(wire)
+}
+
+func (q *Query) UnmarshalJSON(data []byte) error {
+	var wire queryJSON
+	if err := json.Unmarshal(data, &wire); err != nil {
+		return err
+	}
+
+	*q = Query{
+		Property:        wire.Property,
+		SimpleOperator:  wire.SimpleOperator,
+		LeftOperand:     wire.LeftOperand,
+		LogicalOperator: wire.LogicalOperator,
+		RightOperand:    wire.RightOperand,
+	}
+	if wire.Value != nil {
+		q.Value = *wire.Value
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+package domain
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestContentBlocksRequestBuilder(t *testing.T) {
+	request, err := NewContentBlocksRequest().
+		Where(Or(
+			In("assetType.name", "htmlblock", "textblock"),
+			And(Equal("category.id", 42), IsNotNull("customerKey")),
+		)).
+		AndWhere(Equal("status.id", 0)).
+		SortBy("id", SortAsc).
+		Fields("id", "content").
+		PageSize(50).
+		Build()
+	require.NoError(t, err)
+
+	data, err := json.Marshal(request)
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"page": {"page": 0, "pageSize": 50},
+		"query": {
+			"leftOperand": {
+				"leftOperand": {"property": "assetType.name", "simpleOperator": "in", "value": ["htmlblock", "textblock"]},
+				"logicalOperator": "OR",
+				"rightOperand": {
+					"leftOperand": {"property": "category.id", "simpleOperator": "equal", "value": 42},
+					"logicalOperator": "AND",
+					"rightOperand": {"property": "customerKey", "simpleOperator": "isNotNull"}
+				}
+			},
+			"logicalOperator": "AND",
+			"rightOperand": {"property": "status.id", "simpleOperator": "equal", "value": 0}
+		},
+		"sort": [{"property": "id", "direction": "ASC"}],
+		"fields": ["id", "content"]
+	}`, string(data))
+}
+
+func TestContentBlocksRequest_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		request ContentBlocksRequest
+		wantErr require.ErrorAssertionFunc
+	}{
+		{
+			name:    "Empty request",
+			request: ContentBlocksRequest{},
+			wantErr: require.NoError,
+		},
+		{
+			name:    "Negative page size",
+			request: ContentBlocksRequest{Page: Page{PageSize: -1}},
+			wantErr: require.Error,
+		},
+		{
+			name:    "Unknown simple operator",
+			request: ContentBlocksRequest{Query: NewSimpleQuery("name", "between", "a")},
+			wantErr: require.Error,
+		},
+		{
+			name:    "Missing value",
+			request: ContentBlocksRequest{Query: Equal("name", nil)},
+			wantErr: require.Error,
+		},
+		{
+			name:    "Empty in list",
+			request: ContentBlocksRequest{Query: In[int]("id")},
+			wantErr: require.Error,
+		},
+		{
+			name: "Nested operand missing",
+			request: ContentBlocksRequest{Query: And(
+				Equal("name", "footer"),
+				&Query{LeftOperand: Equal("id", 1), LogicalOperator: LogicalOr},
+			)},
+			wantErr: require.Error,
+		},
+		{
+			name:    "Unknown sort direction",
+			request: ContentBlocksRequest{Sort: []Sort{{Property: "id", Direction: "UP"}}},
+			wantErr: require.Error,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.wantErr(t, tt.request.Validate())
+		})
+	}
+}
+
+func TestQuery_JSONRoundTrip(t *testing.T) {
+	query := And(Equal("name", ""), Or(IsNull("description"), Equal("version", 1)))
+
+	data, err := json.Marshal(query)
+	require.NoError(t, err)
+
+	var decoded Query
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	require.NoError(t, decoded.Validate())
+	require.Equal(t, "", decoded.LeftOperand.Value)
+	require.Equal(t, LogicalOr, decoded.RightOperand.LogicalOperator)
+}
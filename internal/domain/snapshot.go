@@ -0,0 +1,44 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+// SnapshotManifest tells whether a content blocks snapshot is complete. A partial snapshot
+// was accepted by the fetcher although some pages failed, they are listed.
+type SnapshotManifest struct {
+	Partial     bool         `json:"partial"`
+	TotalPages  int          `json:"totalPages,omitempty"`
+	FailedPages []FailedPage `json:"failedPages,omitempty"`
+	CreatedAt   time.Time    `json:"createdAt"`
+}
+
+// FailedPage is a page missing from a partial snapshot
+type FailedPage struct {
+	BusinessUnitID int    `json:"businessUnitId,omitempty"`
+	Page           int    `json:"page"`
+	Error          string `json:"error"`
+}
+
+// NewSnapshotManifest describes the snapshot uploaded along the given fetch error,
+// nil for a complete snapshot or the PartialResultError accepted by the fetcher
+func NewSnapshotManifest(fetchErr error, createdAt time.Time) SnapshotManifest {
+	manifest := SnapshotManifest{CreatedAt: createdAt}
+
+	var partialErr *PartialResultError
+	if !errors.As(fetchErr, &partialErr) {
+		return manifest
+	}
+
+	manifest.Partial = true
+	manifest.TotalPages = partialErr.TotalPages
+	for _, pageErr := range partialErr.FailedPages {
+		failedPage := FailedPage{BusinessUnitID: pageErr.BusinessUnitID, Page: pageErr.Page}
+		if pageErr.Err != nil {
+			failedPage.Error = pageErr.Err.Error()
+		}
+		manifest.FailedPages = append(manifest.FailedPages, failedPage)
+	}
+	return manifest
+}
@@ -0,0 +1,34 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewSnapshotManifest(t *testing.T) {
+	createdAt := time.Date(2024, 12, 1, 0, 0, 0, 0, time.UTC)
+
+	require.Equal(t, SnapshotManifest{CreatedAt: createdAt}, NewSnapshotManifest(nil, createdAt))
+
+	partialErr := &PartialResultError{
+		TotalPages: 4,
+		FailedPages: []PageError{
+			{Page: 2, Err: errors.New("timeout")},
+			{BusinessUnitID: 200, Page: 3, Err: errors.New("server error")},
+		},
+		Accepted: true,
+	}
+	require.Equal(t, SnapshotManifest{
+		Partial:    true,
+		TotalPages: 4,
+		FailedPages: []FailedPage{
+			{Page: 2, Error: "timeout"},
+			{BusinessUnitID: 200, Page: 3, Error: "server error"},
+		},
+		CreatedAt: createdAt,
+	}, NewSnapshotManifest(fmt.Errorf("failed to stream content blocks: %w", partialErr), createdAt))
+}
@@ -0,0 +1,25 @@
+package domain
+
+import "time"
+
+// AccessToken is an API access token shared between runs and processes through a TokenStore
+type AccessToken struct {
+	AccessToken string    `json:"accessToken"`
+	InstanceURL string    `json:"instanceUrl"`
+	ExpiresAt   time.Time `json:"expiresAt"`
+	// RefreshToken is issued by the refresh_token grant, it is rotated on every use
+	// and outlives the access token it came with
+	RefreshToken string `json:"refreshToken,omitempty"`
+	// IssuedAt tells the latest of two refresh tokens apart
+	IssuedAt time.Time `json:"issuedAt"`
+}
+
+// ValidFor reports whether the token is still valid for at least the given duration
+func (t AccessToken) ValidFor(duration time.Duration) bool {
+	return t.AccessToken != "" && time.Until(t.ExpiresAt) > duration
+}
+
+// Expired reports whether nothing in the token can be used anymore
+func (t AccessToken) Expired() bool {
+	return t.RefreshToken == "" && !t.ValidFor(0)
+}
@@ -0,0 +1,70 @@
+package domain
+
+import (
+	"cmp"
+	"slices"
+	"time"
+)
+
+// IndexedAsset is what is remembered of an asset between runs to detect its deletion
+type IndexedAsset struct {
+	ID             int    `json:"id"`
+	CustomerKey    string `json:"customerKey,omitempty"`
+	Name           string `json:"name,omitempty"`
+	BusinessUnitID int    `json:"businessUnitId,omitempty"`
+	// LastSeen is the time of the last run, and so the snapshot, the asset was fetched in
+	LastSeen time.Time `json:"lastSeen"`
+}
+
+// Tombstone records an asset which disappeared from Content Builder since the previous run
+type Tombstone struct {
+	ID             int    `json:"id"`
+	CustomerKey    string `json:"customerKey,omitempty"`
+	Name           string `json:"name,omitempty"`
+	BusinessUnitID int    `json:"businessUnitId,omitempty"`
+	// LastSeenSnapshot is the time of the last run, and so the snapshot, still holding the asset
+	LastSeenSnapshot time.Time `json:"lastSeenSnapshot"`
+	DetectedAt       time.Time `json:"detectedAt"`
+}
+
+// IndexAsset keeps the identity of an asset fetched by the run at the given time
+func IndexAsset(asset Asset, seen time.Time) IndexedAsset {
+	return IndexedAsset{
+		ID:             asset.ID,
+		CustomerKey:    asset.CustomerKey,
+		Name:           asset.Name,
+		BusinessUnitID: asset.BusinessUnitID,
+		LastSeen:       seen,
+	}
+}
+
+// DetectDeletions returns a tombstone for every asset of the previous index missing
+// from the current one, ordered by business unit and ID. Assets are identified by
+// business unit and ID, as an asset shared by a parent business unit is fetched from each.
+func DetectDeletions(previous, current []IndexedAsset, detectedAt time.Time) []Tombstone {
+	type assetKey struct{ businessUnitID, id int }
+	seen := make(map[assetKey]bool, len(current))
+	for _, asset := range current {
+		seen[assetKey{asset.BusinessUnitID, asset.ID}] = true
+	}
+
+	var tombstones []Tombstone
+	for _, asset := range previous {
+		if seen[assetKey{asset.BusinessUnitID, asset.ID}] {
+			continue
+		}
+		tombstones = append(tombstones, Tombstone{
+			ID:               asset.ID,
+			CustomerKey:      asset.CustomerKey,
+			Name:             asset.Name,
+			BusinessUnitID:   asset.BusinessUnitID,
+			LastSeenSnapshot: asset.LastSeen,
+			DetectedAt:       detectedAt,
+		})
+	}
+
+	slices.SortFunc(tombstones, func(a, b Tombstone) int {
+		return cmp.Or(cmp.Compare(a.BusinessUnitID, b.BusinessUnitID), cmp.Compare(a.ID, b.ID))
+	})
+	return tombstones
+}
@@ -0,0 +1,33 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectDeletions(t *testing.T) {
+	lastRun := time.Date(2024, 12, 1, 0, 0, 0, 0, time.UTC)
+	now := lastRun.Add(24 * time.Hour)
+
+	previous := []IndexedAsset{
+		{ID: 3, Name: "Footer", BusinessUnitID: 100, LastSeen: lastRun},
+		{ID: 1, Name: "Header", LastSeen: lastRun},
+		{ID: 2, Name: "Banner", LastSeen: lastRun},
+		{ID: 3, Name: "Footer", BusinessUnitID: 200, LastSeen: lastRun},
+	}
+	current := []IndexedAsset{
+		{ID: 1, Name: "Header renamed"},
+		{ID: 3, BusinessUnitID: 200},
+		{ID: 4, Name: "New"},
+	}
+
+	got := DetectDeletions(previous, current, now)
+
+	require.Equal(t, []Tombstone{
+		{ID: 2, Name: "Banner", LastSeenSnapshot: lastRun, DetectedAt: now},
+		{ID: 3, Name: "Footer", BusinessUnitID: 100, LastSeenSnapshot: lastRun, DetectedAt: now},
+	}, got)
+	require.Empty(t, DetectDeletions(nil, current, now))
+}
@@ -0,0 +1,130 @@
+package salesforce
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"jet-example/internal/domain"
+)
+
+// GetAsset fetches a single asset by its ID, looking for it in every business unit.
+// Filters do not apply, an asset asked for by ID is always returned.
+func (c *client) GetAsset(ctx context.Context, id int) (domain.Asset, error) {
+	return c.getAsset(ctx, fmt.Sprintf("asset %d", id), func(accountID int) (domain.Asset, bool, error) {
+		return c.fetchAssetByID(ctx, accountID, id)
+	})
+}
+
+// GetAssetByKey fetches a single asset by its customer key, looking for it in every business unit
+func (c *client) GetAssetByKey(ctx context.Context, customerKey string) (domain.Asset, error) {
+	if customerKey == "" {
+		return domain.Asset{}, errors.New("missing customer key")
+	}
+
+	return c.getAsset(ctx, fmt.Sprintf("asset %q", customerKey), func(accountID int) (domain.Asset, bool, error) {
+		return c.fetchAssetByKey(ctx, accountID, customerKey)
+	})
+}
+
+// getAsset looks the asset up in the business units in configuration order,
+// the first one holding it wins
+func (c *client) getAsset(
+	ctx context.Context,
+	name string,
+	lookup func(accountID int) (domain.Asset, bool, error),
+) (domain.Asset, error) {
+	for _, accountID := range c.config.accountIDs() {
+		asset, found, err := lookup(accountID)
+		if err != nil {
+			return domain.Asset{}, c.businessUnitError(accountID, fmt.Errorf("failed to fetch %s: %w", name, err))
+		}
+		if !found {
+			continue
+		}
+
+		asset.BusinessUnitID = accountID
+		assets := []domain.Asset{asset}
+		if c.config.ResolveCategoryPaths {
+			categories, err := c.cachedCategoryTree(ctx, accountID)
+			if err != nil {
+				return domain.Asset{}, c.businessUnitError(accountID, err)
+			}
+			categories.attachCategoryPaths(assets)
+		}
+		c.attachCompositions(assets)
+
+		return assets[0], nil
+	}
+
+	return domain.Asset{}, fmt.Errorf("%s: %w", name, domain.ErrAssetNotFound)
+}
+
+// fetchAssetByID fetches an asset from the asset endpoint of a business unit
+func (c *client) fetchAssetByID(ctx context.Context, accountID int, id int) (asset domain.Asset, found bool, err error) {
+	err = c.callWithToken(ctx, accountID, func(tokenResponse TokenResponse) error {
+		asset, err = c.fetchSingleAsset(ctx, tokenResponse, id)
+		return err
+	})
+	if isNotFound(err) {
+		return domain.Asset{}, false, nil
+	}
+	if err != nil {
+		return domain.Asset{}, false, err
+	}
+
+	return asset, true, nil
+}
+
+// fetchAssetByKey queries the assets of a business unit for the customer key,
+// which is unique within a business unit
+func (c *client) fetchAssetByKey(ctx context.Context, accountID int, customerKey string) (domain.Asset, bool, error) {
+	request := domain.ContentBlocksRequest{
+		Page:  domain.Page{Page: 1, PageSize: 1},
+		Query: domain.Equal("customerKey", customerKey),
+	}
+
+	response, err := c.fetchAssetPage(ctx, accountID, request)
+	if err != nil {
+		return domain.Asset{}, false, err
+	}
+	if len(response.Items) == 0 {
+		return domain.Asset{}, false, nil
+	}
+
+	return response.Items[0], true, nil
+}
+
+func (c *client) fetchSingleAsset(
+	ctx context.Context,
+	tokenResponse TokenResponse,
+	id int,
+) (domain.Asset, error) {
+	assetURL := tokenResponse.RestInstanceURL + "/asset/v1/content/assets/" + strconv.Itoa(id)
+
+	httpRequest, err := http.NewRequestWithContext(ctx, http.MethodGet, assetURL, nil)
+	if err != nil {
+		return domain.Asset{}, fmt.Errorf("failed to create HTTP request: %w", err)
+	}
+	httpRequest.Header.Set("Authorization", "Bearer "+tokenResponse.AccessToken)
+
+	httpResponse, err := c.httpClient.Do(httpRequest)
+	if err != nil {
+		return domain.Asset{}, fmt.Errorf("failed to perform HTTP request: %w", err)
+	}
+	defer httpResponse.Body.Close()
+
+	if httpResponse.StatusCode != http.StatusOK {
+		return domain.Asset{}, newStatusError("fetch asset", httpResponse)
+	}
+
+	var asset domain.Asset
+	if err := json.NewDecoder(httpResponse.Body).Decode(&asset); err != nil {
+		return domain.Asset{}, fmt.Errorf("failed to decode response body: %w", err)
+	}
+
+	return asset, nil
+}
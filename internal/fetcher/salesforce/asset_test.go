@@ -0,0 +1,170 @@
+package salesforce
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/patrickmn/go-cache"
+	"github.com/stretchr/testify/require"
+
+	"jet-example/internal/domain"
+)
+
+func TestSalesforceClient_GetAsset(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token := r.Header.Get("Authorization")
+		switch {
+		case r.URL.Path == "/asset/v1/content/assets/42" && token == "Bearer token:200":
+			json.NewEncoder(w).Encode(domain.Asset{ID: 42, CustomerKey: "footer", Category: domain.Category{ID: 2}})
+		case r.URL.Path == "/asset/v1/content/assets/query":
+			var request domain.ContentBlocksRequest
+			json.NewDecoder(r.Body).Decode(&request)
+			require.Equal(t, domain.Equal("customerKey", request.Query.Value.(string)), request.Query)
+
+			response := ContentAssetsResponse{Page: 1, PageSize: 1}
+			if request.Query.Value == "footer" && token == "Bearer token:200" {
+				response.Count = 1
+				response.Items = []domain.Asset{{ID: 42, CustomerKey: "footer", Category: domain.Category{ID: 2}}}
+			}
+			json.NewEncoder(w).Encode(response)
+		case r.URL.Path == "/asset/v1/content/categories":
+			json.NewEncoder(w).Encode(CategoriesResponse{
+				Count:    2,
+				Page:     1,
+				PageSize: categoriesPageSize,
+				Items: []domain.Category{
+					{ID: 1, Name: "Content Builder"},
+					{ID: 2, Name: "Footers", ParentID: 1},
+				},
+			})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer mockServer.Close()
+
+	tests := []struct {
+		name      string
+		get       func(c *client) (domain.Asset, error)
+		wantAsset domain.Asset
+		wantErr   require.ErrorAssertionFunc
+	}{
+		{
+			name: "Asset found by ID in second business unit",
+			get: func(c *client) (domain.Asset, error) {
+				return c.GetAsset(context.Background(), 42)
+			},
+			wantAsset: domain.Asset{
+				ID:             42,
+				CustomerKey:    "footer",
+				Category:       domain.Category{ID: 2, Path: "Content Builder/Footers"},
+				BusinessUnitID: 200,
+			},
+			wantErr: require.NoError,
+		},
+		{
+			name: "Asset found by customer key in second business unit",
+			get: func(c *client) (domain.Asset, error) {
+				return c.GetAssetByKey(context.Background(), "footer")
+			},
+			wantAsset: domain.Asset{
+				ID:             42,
+				CustomerKey:    "footer",
+				Category:       domain.Category{ID: 2, Path: "Content Builder/Footers"},
+				BusinessUnitID: 200,
+			},
+			wantErr: require.NoError,
+		},
+		{
+			name: "Unknown ID",
+			get: func(c *client) (domain.Asset, error) {
+				return c.GetAsset(context.Background(), 7)
+			},
+			wantErr: func(t require.TestingT, err error, _ ...interface{}) {
+				require.ErrorIs(t, err, domain.ErrAssetNotFound)
+			},
+		},
+		{
+			name: "Unknown customer key",
+			get: func(c *client) (domain.Asset, error) {
+				return c.GetAssetByKey(context.Background(), "header")
+			},
+			wantErr: func(t require.TestingT, err error, _ ...interface{}) {
+				require.ErrorIs(t, err, domain.ErrAssetNotFound)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &client{
+				config: Config{
+					AuthURL:              mockServer.URL,
+					AccountIDs:           []int{100, 200},
+					ResolveCategoryPaths: true,
+				},
+				httpClient: http.DefaultClient,
+				cache:      cache.New(5*time.Minute, 10*time.Minute),
+			}
+			for _, accountID := range c.config.AccountIDs {
+				c.cache.Set(accessTokenCacheKey(accountID), "token:"+strconv.Itoa(accountID), cache.DefaultExpiration)
+				c.cache.Set(restInstanceURLCacheKey(accountID), mockServer.URL, cache.DefaultExpiration)
+			}
+
+			got, err := tt.get(c)
+
+			tt.wantErr(t, err)
+			got.Raw = nil
+			require.Equal(t, tt.wantAsset, got)
+		})
+	}
+}
+
+func TestSalesforceClient_GetAssetCategoryTreeCached(t *testing.T) {
+	var categoryRequests atomic.Int32
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/asset/v1/content/categories":
+			categoryRequests.Add(1)
+			json.NewEncoder(w).Encode(CategoriesResponse{
+				Count:    1,
+				Page:     1,
+				PageSize: categoriesPageSize,
+				Items:    []domain.Category{{ID: 1, Name: "Content Builder"}},
+			})
+		default:
+			id, _ := strconv.Atoi(path.Base(r.URL.Path))
+			json.NewEncoder(w).Encode(domain.Asset{ID: id, Category: domain.Category{ID: 1}})
+		}
+	}))
+	defer mockServer.Close()
+
+	c := &client{
+		config:     Config{AuthURL: mockServer.URL, ResolveCategoryPaths: true},
+		httpClient: http.DefaultClient,
+		cache:      cache.New(5*time.Minute, 10*time.Minute),
+	}
+	c.cache.Set(cacheKeyAccessTokenKey, "testAccessToken", cache.DefaultExpiration)
+	c.cache.Set(cacheKeyRestInstanceURLKey, mockServer.URL, cache.DefaultExpiration)
+
+	// the assets of a run share the category tree
+	for _, id := range []int{1, 2, 3} {
+		asset, err := c.GetAsset(context.Background(), id)
+		require.NoError(t, err)
+		require.Equal(t, "Content Builder", asset.Category.Path)
+	}
+	require.Equal(t, int32(1), categoryRequests.Load())
+
+	// the next run fetches it again
+	c.StartRun()
+	_, err := c.GetAsset(context.Background(), 1)
+	require.NoError(t, err)
+	require.Equal(t, int32(2), categoryRequests.Load())
+}
@@ -0,0 +1,167 @@
+package salesforce
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"jet-example/internal/domain"
+)
+
+// categoriesPageSize is the largest page the categories endpoint serves
+const categoriesPageSize = 500
+
+type CategoriesResponse struct {
+	Count    int               `json:"count"`
+	Page     int               `json:"page"`
+	PageSize int               `json:"pageSize"`
+	Items    []domain.Category `json:"items"`
+}
+
+// fetchCategories fetches every Content Builder category of a business unit page by page
+func (c *client) fetchCategories(ctx context.Context, accountID int) ([]domain.Category, error) {
+	var categories []domain.Category
+	for page := 1; ; page++ {
+		var response CategoriesResponse
+		err := c.callWithToken(ctx, accountID, func(tokenResponse TokenResponse) (err error) {
+			response, err = c.fetchSingleCategoryPage(ctx, tokenResponse, page)
+			return err
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch categories page %d: %w", page, err)
+		}
+
+		categories = append(categories, response.Items...)
+		if len(response.Items) == 0 || page*response.PageSize >= response.Count {
+			return categories, nil
+		}
+	}
+}
+
+// categoryTreeCacheExpiration is how long the category tree of single asset lookups
+// is reused, so syncing a list of assets fetches it once per business unit
+const categoryTreeCacheExpiration = 5 * time.Minute
+
+func categoryTreeCacheKey(accountID int) string {
+	return businessUnitCacheKey("categoryTree", accountID)
+}
+
+// cachedCategoryTree returns the category tree of a business unit, fetching it unless
+// cached by an earlier lookup of the run. The tree is read only once built.
+func (c *client) cachedCategoryTree(ctx context.Context, accountID int) (*categoryTree, error) {
+	if tree, found := c.cache.Get(categoryTreeCacheKey(accountID)); found {
+		return tree.(*categoryTree), nil
+	}
+
+	categories, err := c.fetchCategories(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+	tree := newCategoryTree(categories)
+	c.cache.Set(categoryTreeCacheKey(accountID), tree, categoryTreeCacheExpiration)
+	return tree, nil
+}
+
+// fetchSingleCategoryPage fetches a single page of categories
+func (c *client) fetchSingleCategoryPage(
+	ctx context.Context,
+	tokenResponse TokenResponse,
+	page int,
+) (CategoriesResponse, error) {
+	query := url.Values{}
+	query.Set("$page", strconv.Itoa(page))
+	query.Set("$pagesize", strconv.Itoa(categoriesPageSize))
+	categoriesURL := tokenResponse.RestInstanceURL + "/asset/v1/content/categories?" + query.Encode()
+
+	httpRequest, err := http.NewRequestWithContext(ctx, http.MethodGet, categoriesURL, nil)
+	if err != nil {
+		return CategoriesResponse{}, fmt.Errorf("failed to create HTTP request: %w", err)
+	}
+	httpRequest.Header.Set("Authorization", "Bearer "+tokenResponse.AccessToken)
+
+	httpResponse, err := c.httpClient.Do(httpRequest)
+	if err != nil {
+		return CategoriesResponse{}, fmt.Errorf("failed to perform HTTP request: %w", err)
+	}
+	defer httpResponse.Body.Close()
+
+	if httpResponse.StatusCode != http.StatusOK {
+		return CategoriesResponse{}, newStatusError("fetch categories", httpResponse)
+	}
+
+	var response CategoriesResponse
+	if err := json.NewDecoder(httpResponse.Body).Decode(&response); err != nil {
+		return CategoriesResponse{}, fmt.Errorf("failed to decode response body: %w", err)
+	}
+
+	return response, nil
+}
+
+// categoryTree indexes the Content Builder category hierarchy of a run
+type categoryTree struct {
+	categories map[int]domain.Category
+	children   map[int][]int
+}
+
+func newCategoryTree(categories []domain.Category) *categoryTree {
+	tree := &categoryTree{
+		categories: make(map[int]domain.Category, len(categories)),
+		children:   make(map[int][]int),
+	}
+	for _, category := range categories {
+		tree.categories[category.ID] = category
+		tree.children[category.ParentID] = append(tree.children[category.ParentID], category.ID)
+	}
+	return tree
+}
+
+// path returns the folder path of a category e.g. "Content Builder/Newsletters/Footers",
+// or an empty string for an unknown category
+func (t *categoryTree) path(id int) string {
+	var names []string
+	seen := make(map[int]bool)
+	for id != 0 && !seen[id] {
+		category, found := t.categories[id]
+		if !found {
+			break
+		}
+		seen[id] = true
+		names = append(names, category.Name)
+		id = category.ParentID
+	}
+
+	slices.Reverse(names)
+	return strings.Join(names, "/")
+}
+
+// descendantIDs returns the given category IDs together with all their subcategories
+func (t *categoryTree) descendantIDs(rootIDs []int) []int {
+	seen := make(map[int]bool)
+	var ids []int
+	queue := append([]int(nil), rootIDs...)
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		ids = append(ids, id)
+		queue = append(queue, t.children[id]...)
+	}
+
+	return ids
+}
+
+// attachCategoryPaths sets the folder path of each asset's category
+func (t *categoryTree) attachCategoryPaths(assets []domain.Asset) {
+	for i := range assets {
+		assets[i].Category.Path = t.path(assets[i].Category.ID)
+	}
+}
@@ -0,0 +1,32 @@
+package salesforce
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"jet-example/internal/domain"
+)
+
+func TestCategoryTree(t *testing.T) {
+	tree := newCategoryTree([]domain.Category{
+		{ID: 1, Name: "Content Builder"},
+		{ID: 2, Name: "Newsletters", ParentID: 1},
+		{ID: 3, Name: "Footers", ParentID: 2},
+		{ID: 4, Name: "Archive", ParentID: 1},
+		{ID: 5, Name: "Loop A", ParentID: 6},
+		{ID: 6, Name: "Loop B", ParentID: 5},
+	})
+
+	require.Equal(t, "Content Builder/Newsletters/Footers", tree.path(3))
+	require.Equal(t, "Content Builder", tree.path(1))
+	require.Equal(t, "", tree.path(99))
+	require.Equal(t, "Loop B/Loop A", tree.path(5)) // cycles end the walk
+
+	require.ElementsMatch(t, []int{2, 3}, tree.descendantIDs([]int{2}))
+	require.ElementsMatch(t, []int{1, 2, 3, 4}, tree.descendantIDs([]int{1}))
+
+	assets := []domain.Asset{{ID: 10, Category: domain.Category{ID: 4}}}
+	tree.attachCategoryPaths(assets)
+	require.Equal(t, "Content Builder/Archive", assets[0].Category.Path)
+}
@@ -4,124 +4,471 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"math"
 	"net/http"
+	"slices"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/patrickmn/go-cache"
 
 	"jet-example/internal/domain"
+	"jet-example/pkg/singleflight"
 )
 
-// Cache keys
+// Cache keys, suffixed with the MID for business units other than the default one
 const (
 	cacheKeyAccessTokenKey     = "accessToken"
 	cacheKeyRestInstanceURLKey = "restInstanceURL"
+	// the refresh token is shared by the business units, its key is suffixed to the client ID instead
+	cacheKeyRefreshTokenKey = "refreshToken"
 )
 
+func accessTokenCacheKey(accountID int) string {
+	return businessUnitCacheKey(cacheKeyAccessTokenKey, accountID)
+}
+
+func restInstanceURLCacheKey(accountID int) string {
+	return businessUnitCacheKey(cacheKeyRestInstanceURLKey, accountID)
+}
+
+func businessUnitCacheKey(key string, accountID int) string {
+	if accountID == defaultAccountID {
+		return key
+	}
+	return key + ":" + strconv.Itoa(accountID)
+}
+
+// session is shared by the clients reading the same SFMC account e.g. the Salesforce
+// and Data Extension clients, so together they keep to one rate limit
+type session struct {
+	limiter *rateLimiter
+	// refreshMu serializes token refreshes after a 401 so concurrent workers refresh once
+	refreshMu sync.Mutex
+	// tokenFlight deduplicates the token lookups of concurrent callers by business unit and scopes
+	tokenFlight singleflight.Group[TokenResponse]
+	// rotationMu serializes the requests using the refresh token, each of them rotates it
+	rotationMu sync.Mutex
+}
+
 type client struct {
 	config     Config
 	httpClient *http.Client
 	cache      *cache.Cache
+	// tokenStore shares tokens between runs and processes, nil keeps them in the process cache only
+	tokenStore domain.TokenStore
+	// session is shared with the clients derived from this one, a client created without one gets its own
+	session     *session
+	sessionOnce sync.Once
+	// budget limits the API calls of a run of this client, nil means unlimited
+	budget *callBudget
+	// requiredScopes must be granted to every token on top of the configured ones
+	requiredScopes []string
 }
 
 func NewSalesforceClient(
 	config Config,
 	httpClient *http.Client,
 	cache *cache.Cache,
+	tokenStore domain.TokenStore,
 ) domain.Fetcher {
 	return &client{
-		config:     config,
-		httpClient: httpClient,
-		cache:      cache,
+		config:         config,
+		httpClient:     httpClient,
+		cache:          cache,
+		tokenStore:     tokenStore,
+		session:        &session{limiter: newRateLimiter(config.RateLimit)},
+		budget:         newCallBudget(config.RateLimit.CallBudget),
+		requiredScopes: defaultRequiredScopes,
 	}
 }
 
-// FetchContentBlocks fetches content blocks from Salesforce concurrently with error handling
+// shared returns the session of the client
+func (c *client) shared() *session {
+	c.sessionOnce.Do(func() {
+		if c.session == nil {
+			c.session = &session{}
+		}
+	})
+	return c.session
+}
+
+// tokenKey scopes a token cache, store or lookup key to the scopes the client requires,
+// a token checked for the scopes of one client is not reused by a client needing others.
+// Keys of the Content Builder scopes are left unchanged so their tokens outlive upgrades.
+func (c *client) tokenKey(key string) string {
+	if len(c.requiredScopes) == 0 || slices.Equal(c.requiredScopes, defaultRequiredScopes) {
+		return key
+	}
+	return key + "|" + strings.Join(c.requiredScopes, ",")
+}
+
+// pageResult is the outcome of fetching a single page of assets
+type pageResult struct {
+	page          int
+	contentBlocks []domain.Asset
+	// count is the total number of assets the page reported
+	count int
+	err   error
+}
+
+func (r pageResult) pageError(accountID int) domain.PageError {
+	return domain.PageError{
+		BusinessUnitID: accountID,
+		Page:           r.page,
+		Err:            r.err,
+	}
+}
+
+// FetchContentBlocks fetches content blocks from every configured business unit,
+// pages of a business unit are fetched concurrently with error handling
 func (c *client) FetchContentBlocks(
 	ctx context.Context,
 	request domain.ContentBlocksRequest,
-) ([]domain.ContentBlock, error) {
-	tokenResponse, err := c.fetchAccessToken(ctx)
-	if err != nil {
-		return nil, fmt.Errorf("failed to fetch access token: %w", err)
+) ([]domain.Asset, error) {
+	if err := c.validate(request); err != nil {
+		return nil, err
+	}
+	stopTokenRefresher := c.startTokenRefresher(ctx)
+	defer stopTokenRefresher()
+
+	allContentBlocks := []domain.Asset{}
+	var totalPages int
+	var failedPages []domain.PageError
+	for _, accountID := range c.config.accountIDs() {
+		businessUnitPages, err := c.fetchBusinessUnitPages(ctx, accountID, request, func(result pageResult) bool {
+			if result.err != nil {
+				failedPages = append(failedPages, result.pageError(accountID))
+				return true
+			}
+			allContentBlocks = append(allContentBlocks, result.contentBlocks...)
+			return true
+		})
+		if err != nil {
+			return nil, err
+		}
+		totalPages += businessUnitPages
+
+		// no point fetching other business units when the result is rejected anyway
+		if len(failedPages) > 0 && (c.config.failsFast() || exceedsBudget(failedPages)) {
+			break
+		}
+	}
+
+	// a run out of budget is stopped whatever the partial failure policy
+	if exceedsBudget(failedPages) {
+		return nil, c.budgetError()
+	}
+
+	// pages cancelled because of an earlier failure are not failures of their own
+	if c.config.failsFast() {
+		failedPages = withoutCancelledPages(failedPages)
+	}
+
+	return c.config.evaluatePartialResult(allContentBlocks, totalPages, failedPages)
+}
+
+// StartRun resets the API call budget, every call until the next run counts against it,
+// and forgets the category trees cached by the previous run
+func (c *client) StartRun() {
+	c.budget.startRun()
+	for _, accountID := range c.config.accountIDs() {
+		c.cache.Delete(categoryTreeCacheKey(accountID))
 	}
+}
+
+// validate checks the request and the configuration before anything is sent
+func (c *client) validate(request domain.ContentBlocksRequest) error {
+	request.Sort = c.config.sortOrder(request.Sort)
+	if err := request.Validate(); err != nil {
+		return fmt.Errorf("invalid content blocks request: %w", err)
+	}
+
+	return c.config.Validate()
+}
+
+// fetchBusinessUnitPages fetches the pages of a single business unit and hands each
+// of them to handle in page order, with its assets filtered by name and tagged
+// with the business unit MID. Returning false from handle stops the fetch.
+// An asset is handed over once, even when it moved between pages during the fetch,
+// and the assets are fetched again by ID range when the pages drifted, those come last
+// and sorted by ID. It returns the number of pages the first page reported, the pages
+// of the ID range pass are not counted so they do not skew the failed pages percentage.
+func (c *client) fetchBusinessUnitPages(
+	ctx context.Context,
+	accountID int,
+	request domain.ContentBlocksRequest,
+	handle func(result pageResult) bool,
+) (int, error) {
+	if _, err := c.fetchAccessToken(ctx, accountID); err != nil {
+		return 0, c.businessUnitError(accountID, fmt.Errorf("failed to fetch access token: %w", err))
+	}
+
+	// the category tree is fetched once per run and business unit, StartRun forgets it
+	var categories *categoryTree
+	if c.needsCategories() {
+		var err error
+		if categories, err = c.cachedCategoryTree(ctx, accountID); err != nil {
+			return 0, c.businessUnitError(accountID, err)
+		}
+	}
+
+	// narrow the request down to the configured asset types and categories
+	request.Query = domain.And(request.Query, c.filterQuery(categories))
+	request.Sort = withStableSort(c.config.sortOrder(request.Sort))
 
 	// fetch the first page to get the total count
 	currentPage := 1
 	request.Page.Page = currentPage
-	firstPageResponse, err := fetchSingleAssetPage(
-		ctx,
-		tokenResponse.RestInstanceURL,
-		tokenResponse.AccessToken,
-		request,
-	)
+	firstPageResponse, err := c.fetchAssetPage(ctx, accountID, request)
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch first page of assets: %w", err)
+		return 0, c.businessUnitError(accountID, fmt.Errorf("failed to fetch first page of assets: %w", err))
 	}
 
 	// calculate the total number of pages
-	totalPages := int(math.Ceil(float64(firstPageResponse.Count) / float64(firstPageResponse.PageSize)))
+	totalPages := 1
+	if firstPageResponse.PageSize > 0 {
+		totalPages = int(math.Ceil(float64(firstPageResponse.Count) / float64(firstPageResponse.PageSize)))
+	}
 
-	// create a channel to receive content blocks from goroutines
-	contentBlockChan := make(chan []domain.ContentBlock, totalPages)
+	// cancelled on the first failed page when failing fast, or when handle stops the fetch
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// create a channel to receive pages from workers,
+	// the first page is already fetched
+	window := c.config.maxConcurrentPages()
+	pageChan := make(chan pageResult, window)
+	if totalPages > 0 {
+		pageChan <- pageResult{page: currentPage, contentBlocks: firstPageResponse.Items, count: firstPageResponse.Count}
+	}
+
+	// queue the remaining pages for the workers
+	jobChan := make(chan int, max(totalPages-1, 0))
+	for page := currentPage + 1; page <= totalPages; page++ {
+		jobChan <- page
+	}
+	close(jobChan)
+
+	// slots bounds the pages fetched but not handed over yet, so workers wait for a slow
+	// consumer instead of piling up pages in memory. A slot is taken before a page is,
+	// which gives the next page to hand over a slot whatever the order pages arrive in.
+	// Once the fetch is cancelled the remaining pages only report the cancellation.
+	slots := make(chan struct{}, window)
+	takeSlot := func() {
+		select {
+		case slots <- struct{}{}:
+		case <-ctx.Done():
+		}
+	}
+	releaseSlot := func() {
+		select {
+		case <-slots:
+		default:
+		}
+	}
+
+	workers := min(window, totalPages-1)
 
 	var wg sync.WaitGroup
-	wg.Add(totalPages)
+	wg.Add(max(workers, 0))
 
-	// launch a goroutine for each page
-	for page := 1; page <= totalPages; page++ {
-		go func(page int) {
+	// launch a bounded pool of workers
+	for worker := 0; worker < workers; worker++ {
+		go func() {
 			defer wg.Done()
-			request.Page.Page = page
-			response, err := fetchSingleAssetPage(
-				ctx,
-				tokenResponse.RestInstanceURL,
-				tokenResponse.AccessToken,
-				request,
-			)
-			if err != nil {
-				// I left tech debt here
-				// not sure if I should fail whole request if problem fetch one page
-				// or continue with other pages
-				contentBlockChan <- []domain.ContentBlock{} // Send an empty slice
-				fmt.Printf("Error fetching page %d: %v\n", page, err)
-				return
+			for {
+				takeSlot()
+				page, ok := <-jobChan
+				if !ok {
+					releaseSlot()
+					return
+				}
+
+				// do not start new pages once the fetch has been cancelled
+				if ctx.Err() != nil {
+					pageChan <- pageResult{page: page, err: ctx.Err()}
+					continue
+				}
+
+				pageRequest := request
+				pageRequest.Page.Page = page
+				response, err := c.fetchAssetPage(ctx, accountID, pageRequest)
+				if err != nil && (c.config.failsFast() || errors.Is(err, ErrAPICallBudgetExceeded)) {
+					cancel()
+				}
+				pageChan <- pageResult{page: page, contentBlocks: response.Items, count: response.Count, err: err}
 			}
-			contentBlockChan <- response.Items
-		}(page)
+		}()
 	}
 
-	// wait for all goroutines to finish
-	wg.Wait()
-	close(contentBlockChan)
+	// close the channel once all workers are finished
+	go func() {
+		wg.Wait()
+		close(pageChan)
+	}()
+
+	consistency := newPageConsistency(firstPageResponse.Count)
+	prepare := func(result pageResult) pageResult {
+		if result.err == nil {
+			result.contentBlocks = consistency.check(result.count, result.contentBlocks)
+			result.contentBlocks = c.config.Filter.filterByName(result.contentBlocks)
+			if c.config.ResolveCategoryPaths {
+				categories.attachCategoryPaths(result.contentBlocks)
+			}
+			c.attachCompositions(result.contentBlocks)
+			for i := range result.contentBlocks {
+				result.contentBlocks[i].BusinessUnitID = accountID
+			}
+		}
+		return result
+	}
 
-	// collect results from the channel
-	var allContentBlocks []domain.ContentBlock
-	for blocks := range contentBlockChan {
-		allContentBlocks = append(allContentBlocks, blocks...)
+	// hand over the results in page order, the channel is drained even
+	// once handle stopped the fetch so no worker is left blocked
+	order := newPageOrder(currentPage)
+	stopped := false
+	for result := range pageChan {
+		if stopped {
+			continue
+		}
+		for _, ready := range order.add(result) {
+			if !handle(prepare(ready)) {
+				stopped = true
+				cancel()
+				break
+			}
+			// the first page holds no slot
+			if ready.page != currentPage {
+				releaseSlot()
+			}
+		}
+	}
+
+	// assets added or deleted during the fetch shifted the pages, so some may have
+	// been skipped: the ID range pass hands over the ones not seen yet
+	if consistency.drifted && !stopped {
+		logDrift(accountID)
+		afterID := consistency.resumeAfter(request.Sort)
+		c.fetchByIDRange(ctx, accountID, request, afterID, totalPages+1, func(result pageResult) bool {
+			return handle(prepare(result))
+		})
 	}
 
-	return allContentBlocks, nil
+	return totalPages, nil
+}
+
+// exceedsBudget reports whether pages failed because the call budget was spent
+func exceedsBudget(failedPages []domain.PageError) bool {
+	return slices.ContainsFunc(failedPages, func(pageErr domain.PageError) bool {
+		return errors.Is(pageErr.Err, ErrAPICallBudgetExceeded)
+	})
 }
 
-// FetchAccessToken fetches an access token
+// budgetError reports a run stopped by its call budget
+func (c *client) budgetError() error {
+	return fmt.Errorf(
+		"run stopped after %d API calls: %w",
+		c.budget.callsMade(),
+		ErrAPICallBudgetExceeded,
+	)
+}
+
+// businessUnitError names the business unit in errors unless it is the default one
+func (c *client) businessUnitError(accountID int, err error) error {
+	if accountID == defaultAccountID {
+		return err
+	}
+	return fmt.Errorf("business unit %d: %w", accountID, err)
+}
+
+// FetchAccessToken fetches an access token for the given business unit.
+// Concurrent callers missing the cache share a single lookup.
 func (c *client) fetchAccessToken(
 	ctx context.Context,
-) (response TokenResponse, err error) {
+	accountID int,
+) (TokenResponse, error) {
 	// check cache for token and instance URL
-	if cachedToken, found := c.getCachedToken(); found {
+	if cachedToken, found := c.getCachedToken(accountID); found {
 		return cachedToken, nil
 	}
 
+	// the lookup is shared with the callers waiting for it, so it runs detached from the
+	// caller starting it. Every caller stops waiting once its own context is done.
+	lookupCtx := context.WithoutCancel(ctx)
+	lookup := c.shared().tokenFlight.DoChan(c.tokenKey(strconv.Itoa(accountID)), func() (TokenResponse, error) {
+		// cached by a lookup that ended while this caller was on its way
+		if cachedToken, found := c.getCachedToken(accountID); found {
+			return cachedToken, nil
+		}
+
+		// then for a token saved by an earlier run or another process
+		if storedToken, found := c.getStoredToken(lookupCtx, accountID); found {
+			c.cacheToken(accountID, storedToken)
+			return storedToken, nil
+		}
+
+		return c.requestToken(lookupCtx, accountID)
+	})
+
+	select {
+	case result := <-lookup:
+		return result.Value, result.Err
+	case <-ctx.Done():
+		return TokenResponse{}, ctx.Err()
+	}
+}
+
+// requestToken requests a new token, shares it through the token store and caches it
+func (c *client) requestToken(ctx context.Context, accountID int) (tokenResponse TokenResponse, err error) {
+	// a refresh token can be used once, requests of other business units and scopes
+	// wait for it to be rotated
+	if c.config.OAuth.GrantType == GrantRefreshToken {
+		c.shared().rotationMu.Lock()
+		defer c.shared().rotationMu.Unlock()
+	}
+
+	err = c.withRetry(ctx, func() error {
+		tokenResponse, err = c.requestAccessToken(ctx, accountID)
+		return err
+	})
+	if err != nil {
+		return TokenResponse{}, err
+	}
+
+	// the refresh token the request used is no longer valid, whatever the scopes
+	c.keepRefreshToken(ctx, tokenResponse.RefreshToken)
+	if err := c.checkScopes(tokenResponse); err != nil {
+		return TokenResponse{}, err
+	}
+	c.saveStoredToken(ctx, accountID, tokenResponse)
+	c.cacheToken(accountID, tokenResponse)
+
+	return tokenResponse, nil
+}
+
+// cacheToken stores the token and instance URL in the cache until the expiry margin,
+// a token expiring sooner is not cached
+func (c *client) cacheToken(accountID int, tokenResponse TokenResponse) {
+	expiration := time.Duration(tokenResponse.ExpiresIn)*time.Second - tokenExpiryMargin
+	if expiration <= 0 {
+		return
+	}
+	c.cache.Set(c.tokenKey(accessTokenCacheKey(accountID)), tokenResponse.AccessToken, expiration)
+	c.cache.Set(c.tokenKey(restInstanceURLCacheKey(accountID)), tokenResponse.RestInstanceURL, expiration)
+}
+
+// requestAccessToken performs a single call to the token endpoint
+func (c *client) requestAccessToken(ctx context.Context, accountID int) (TokenResponse, error) {
 	authURL := c.config.AuthURL + "/v2/token"
 
-	request := TokenRequest{
-		GrantType:    "client_credentials", // since this is server-to-server integration (according to docs)
-		ClientID:     c.config.ClientID,
-		ClientSecret: c.config.ClientSecret,
+	request, err := c.tokenRequest(ctx, accountID)
+	if err != nil {
+		return TokenResponse{}, err
 	}
 	requestBody, err := json.Marshal(request)
 	if err != nil {
@@ -146,12 +493,7 @@ func (c *client) fetchAccessToken(
 	defer httpResponse.Body.Close()
 
 	if httpResponse.StatusCode != http.StatusOK {
-		if httpResponse.StatusCode == http.StatusUnauthorized {
-			return TokenResponse{}, fmt.Errorf("unauthorized")
-		}
-
-		return TokenResponse{},
-			fmt.Errorf("failed to fetch access token, status code: %d", httpResponse.StatusCode)
+		return TokenResponse{}, newStatusError("fetch access token", httpResponse)
 	}
 
 	var tokenResponse TokenResponse
@@ -159,22 +501,13 @@ func (c *client) fetchAccessToken(
 		return TokenResponse{}, fmt.Errorf("failed to decode response body: %w", err)
 	}
 
-	// store the new token and instance URL in the cache
-	expiration := time.Duration(tokenResponse.ExpiresIn) * time.Second
-	// API documentations recommend that we refresh our token two minutes before its lifetime ends.
-	safeExpiration := expiration - 2*time.Minute
-	if safeExpiration > 0 { // Ensure expiration is not negative
-		c.cache.Set(cacheKeyAccessTokenKey, tokenResponse.AccessToken, expiration)
-		c.cache.Set(cacheKeyRestInstanceURLKey, tokenResponse.RestInstanceURL, expiration)
-	}
-
 	return tokenResponse, nil
 }
 
-// getCachedToken retrieves the token and instance URL from the cache.
-func (c *client) getCachedToken() (TokenResponse, bool) {
-	accessToken, foundToken := c.cache.Get(cacheKeyAccessTokenKey)
-	instanceURL, foundInstance := c.cache.Get(cacheKeyRestInstanceURLKey)
+// getCachedToken retrieves the token and instance URL of a business unit from the cache.
+func (c *client) getCachedToken(accountID int) (TokenResponse, bool) {
+	accessToken, foundToken := c.cache.Get(c.tokenKey(accessTokenCacheKey(accountID)))
+	instanceURL, foundInstance := c.cache.Get(c.tokenKey(restInstanceURLCacheKey(accountID)))
 
 	if foundToken && foundInstance {
 		return TokenResponse{
@@ -186,8 +519,100 @@ func (c *client) getCachedToken() (TokenResponse, bool) {
 	return TokenResponse{}, false
 }
 
-// fetchSinglePage fetches a single page of assets based on the query
-func fetchSingleAssetPage(
+// fetchAssetPage fetches a single page of assets with the current access token
+func (c *client) fetchAssetPage(
+	ctx context.Context,
+	accountID int,
+	request domain.ContentBlocksRequest,
+) (response ContentAssetsResponse, err error) {
+	err = c.callWithToken(ctx, accountID, func(tokenResponse TokenResponse) error {
+		response, err = c.fetchSingleAssetPage(
+			ctx,
+			tokenResponse.RestInstanceURL,
+			tokenResponse.AccessToken,
+			request,
+		)
+		return err
+	})
+	return response, err
+}
+
+// callWithToken runs call with the current access token of a business unit,
+// retrying transient failures and refreshing the token once if SFMC rejected it
+func (c *client) callWithToken(
+	ctx context.Context,
+	accountID int,
+	call func(tokenResponse TokenResponse) error,
+) error {
+	return c.callWithTokenBy(ctx, accountID, c.withRetry, call)
+}
+
+// callOnceWithToken runs call with the current access token of a business unit without
+// retrying transient failures, for calls that are not idempotent. A call rejected for its
+// token was not processed, so it is still sent again once the token is refreshed.
+func (c *client) callOnceWithToken(
+	ctx context.Context,
+	accountID int,
+	call func(tokenResponse TokenResponse) error,
+) error {
+	return c.callWithTokenBy(ctx, accountID, c.withoutRetry, call)
+}
+
+// callWithTokenBy runs call through send with the current access token of a business unit,
+// refreshing the token once if SFMC rejected it
+func (c *client) callWithTokenBy(
+	ctx context.Context,
+	accountID int,
+	send func(ctx context.Context, operation func() error) error,
+	call func(tokenResponse TokenResponse) error,
+) error {
+	tokenResponse, err := c.fetchAccessToken(ctx, accountID)
+	if err != nil {
+		return fmt.Errorf("failed to fetch access token: %w", err)
+	}
+
+	err = send(ctx, func() error {
+		return call(tokenResponse)
+	})
+	if !isUnauthorized(err) {
+		return err
+	}
+
+	// token revoked or expired early, a second 401 is final
+	tokenResponse, err = c.refreshAccessToken(ctx, accountID, tokenResponse.AccessToken)
+	if err != nil {
+		return fmt.Errorf("failed to refresh access token: %w", err)
+	}
+
+	return send(ctx, func() error {
+		return call(tokenResponse)
+	})
+}
+
+// refreshAccessToken evicts the rejected token from the cache and fetches a new one.
+// Workers rejected with the same token wait for the first refresh and reuse its result.
+func (c *client) refreshAccessToken(
+	ctx context.Context,
+	accountID int,
+	rejectedToken string,
+) (TokenResponse, error) {
+	c.shared().refreshMu.Lock()
+	defer c.shared().refreshMu.Unlock()
+
+	if cachedToken, found := c.getCachedToken(accountID); found {
+		if cachedToken.AccessToken != rejectedToken {
+			return cachedToken, nil // already refreshed by another worker
+		}
+		c.cache.Delete(c.tokenKey(accessTokenCacheKey(accountID)))
+		c.cache.Delete(c.tokenKey(restInstanceURLCacheKey(accountID)))
+	}
+	c.evictStoredToken(ctx, accountID, rejectedToken)
+
+	return c.fetchAccessToken(ctx, accountID)
+}
+
+// fetchSingleAssetPage fetches a single page of assets based on the query
+func (c *client) fetchSingleAssetPage(
 	ctx context.Context,
 	instanceURL,
 	accessToken string,
@@ -212,15 +637,14 @@ func fetchSingleAssetPage(
 	httpRequest.Header.Set("Authorization", "Bearer "+accessToken)
 	httpRequest.Header.Set("Content-Type", "application/json")
 
-	httpResponse, err := http.DefaultClient.Do(httpRequest)
+	httpResponse, err := c.httpClient.Do(httpRequest)
 	if err != nil {
 		return ContentAssetsResponse{}, fmt.Errorf("failed to perform HTTP request: %w", err)
 	}
 	defer httpResponse.Body.Close()
 
 	if httpResponse.StatusCode != http.StatusOK {
-		return ContentAssetsResponse{},
-			fmt.Errorf("failed to fetch assets, status code: %d", httpResponse.StatusCode)
+		return ContentAssetsResponse{}, newStatusError("fetch assets", httpResponse)
 	}
 
 	var response ContentAssetsResponse
@@ -3,8 +3,10 @@ package salesforce
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -12,6 +14,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"jet-example/internal/domain"
+	"jet-example/pkg/transport"
 )
 
 func TestSalesforceClient_fetchAccessToken(t *testing.T) {
@@ -92,7 +95,7 @@ func TestSalesforceClient_fetchAccessToken(t *testing.T) {
 				cache:      cacheInstance,
 			}
 
-			token, err := c.fetchAccessToken(context.Background())
+			token, err := c.fetchAccessToken(context.Background(), defaultAccountID)
 
 			tt.wantErr(t, err)
 			require.Equal(t, tt.expectedToken, token)
@@ -104,11 +107,13 @@ func TestSalesforceClient_fetchAccessToken(t *testing.T) {
 
 func TestSalesforceClient_FetchContentBlocks(t *testing.T) {
 	tests := []struct {
-		name              string
-		mockServerHandler func(w http.ResponseWriter, r *http.Request)
-		request           domain.ContentBlocksRequest
-		want              []domain.ContentBlock
-		wantErr           require.ErrorAssertionFunc
+		name                  string
+		policy                PartialFailurePolicy
+		maxFailedPagesPercent float64
+		mockServerHandler     func(w http.ResponseWriter, r *http.Request)
+		request               domain.ContentBlocksRequest
+		want                  []domain.Asset
+		wantErr               require.ErrorAssertionFunc
 	}{
 		{
 			name: "Success - Single Page",
@@ -117,7 +122,7 @@ func TestSalesforceClient_FetchContentBlocks(t *testing.T) {
 					Count:    2,
 					Page:     1,
 					PageSize: 10,
-					Items: []domain.ContentBlock{
+					Items: []domain.Asset{
 						{Content: "Block 1"},
 						{Content: "Block 2"},
 					},
@@ -125,12 +130,9 @@ func TestSalesforceClient_FetchContentBlocks(t *testing.T) {
 				json.NewEncoder(w).Encode(response)
 			},
 			request: domain.ContentBlocksRequest{
-				Page: struct {
-					Page     int `json:"page"`
-					PageSize int `json:"pageSize"`
-				}{Page: 1, PageSize: 10},
+				Page: domain.Page{Page: 1, PageSize: 10},
 			},
-			want: []domain.ContentBlock{
+			want: []domain.Asset{
 				{Content: "Block 1"},
 				{Content: "Block 2"},
 			},
@@ -148,7 +150,7 @@ func TestSalesforceClient_FetchContentBlocks(t *testing.T) {
 						Count:    3,
 						Page:     1,
 						PageSize: 2,
-						Items: []domain.ContentBlock{
+						Items: []domain.Asset{
 							{Content: "Block 1"},
 							{Content: "Block 2"},
 						},
@@ -159,7 +161,7 @@ func TestSalesforceClient_FetchContentBlocks(t *testing.T) {
 						Count:    3,
 						Page:     2,
 						PageSize: 2,
-						Items: []domain.ContentBlock{
+						Items: []domain.Asset{
 							{Content: "Block 3"},
 						},
 					}
@@ -167,12 +169,9 @@ func TestSalesforceClient_FetchContentBlocks(t *testing.T) {
 				}
 			},
 			request: domain.ContentBlocksRequest{
-				Page: struct {
-					Page     int `json:"page"`
-					PageSize int `json:"pageSize"`
-				}{Page: 1, PageSize: 2},
+				Page: domain.Page{Page: 1, PageSize: 2},
 			},
-			want: []domain.ContentBlock{
+			want: []domain.Asset{
 				{Content: "Block 1"},
 				{Content: "Block 2"},
 				{Content: "Block 3"},
@@ -190,35 +189,68 @@ func TestSalesforceClient_FetchContentBlocks(t *testing.T) {
 			wantErr: require.Error,
 		},
 		{
-			name: "Fetching Subsequent Page - one of page fail to fetch",
+			name: "Error - Invalid Request",
 			mockServerHandler: func(w http.ResponseWriter, r *http.Request) {
-				// Mock a successful response for the first page, but an error for the second
-				var request domain.ContentBlocksRequest
-				json.NewDecoder(r.Body).Decode(&request)
-				page := request.Page.Page
-				if page == 1 {
-					response := ContentAssetsResponse{
-						Count:    3,
-						Page:     1,
-						PageSize: 2,
-						Items: []domain.ContentBlock{
-							{Content: "Block 1"},
-							{Content: "Block 2"},
-						},
-					}
-					json.NewEncoder(w).Encode(response)
-				} else if page == 2 {
-					w.WriteHeader(http.StatusInternalServerError)
-				}
+				t.Fatal("invalid request must not be sent")
 			},
 			request: domain.ContentBlocksRequest{
-				Page: struct {
-					Page     int `json:"page"`
-					PageSize int `json:"pageSize"`
-				}{Page: 1, PageSize: 2},
+				Query: domain.And(domain.Equal("assetType.name", "htmlblock"), &domain.Query{Property: "name"}),
+			},
+			want:    nil,
+			wantErr: require.Error,
+		},
+		{
+			name:              "Fetching Subsequent Page - one of page fail to fetch",
+			mockServerHandler: secondPageFailsHandler,
+			request: domain.ContentBlocksRequest{
+				Page: domain.Page{Page: 1, PageSize: 2},
+			},
+			want: nil, // fail-fast by default
+			wantErr: func(t require.TestingT, err error, _ ...interface{}) {
+				var partialErr *domain.PartialResultError
+				require.ErrorAs(t, err, &partialErr)
+				require.Equal(t, []int{2}, partialErr.FailedPageNumbers())
+			},
+		},
+		{
+			name:              "Fetching Subsequent Page - best effort keeps fetched pages",
+			policy:            PartialFailureBestEffort,
+			mockServerHandler: secondPageFailsHandler,
+			request: domain.ContentBlocksRequest{
+				Page: domain.Page{Page: 1, PageSize: 2},
+			},
+			want: []domain.Asset{{Content: "Block 1"}, {Content: "Block 2"}}, // Partial result
+			wantErr: func(t require.TestingT, err error, _ ...interface{}) {
+				var partialErr *domain.PartialResultError
+				require.ErrorAs(t, err, &partialErr)
+				require.Equal(t, 2, partialErr.TotalPages)
+				require.Equal(t, []int{2}, partialErr.FailedPageNumbers())
+			},
+		},
+		{
+			name:                  "Fetching Subsequent Page - failed pages above threshold",
+			policy:                PartialFailureThreshold,
+			maxFailedPagesPercent: 25,
+			mockServerHandler:     secondPageFailsHandler,
+			request: domain.ContentBlocksRequest{
+				Page: domain.Page{Page: 1, PageSize: 2},
+			},
+			want:    nil,
+			wantErr: require.Error,
+		},
+		{
+			name:                  "Fetching Subsequent Page - failed pages within threshold",
+			policy:                PartialFailureThreshold,
+			maxFailedPagesPercent: 50,
+			mockServerHandler:     secondPageFailsHandler,
+			request: domain.ContentBlocksRequest{
+				Page: domain.Page{Page: 1, PageSize: 2},
+			},
+			want: []domain.Asset{{Content: "Block 1"}, {Content: "Block 2"}},
+			wantErr: func(t require.TestingT, err error, _ ...interface{}) {
+				var partialErr *domain.PartialResultError
+				require.ErrorAs(t, err, &partialErr)
 			},
-			want:    []domain.ContentBlock{{Content: "Block 1"}, {Content: "Block 2"}}, // Partial result
-			wantErr: require.NoError,
 		},
 	}
 
@@ -229,9 +261,11 @@ func TestSalesforceClient_FetchContentBlocks(t *testing.T) {
 
 			c := &client{
 				config: Config{
-					AuthURL:      mockServer.URL, // Use the mock server URL
-					ClientID:     "testClientID",
-					ClientSecret: "testClientSecret",
+					AuthURL:               mockServer.URL, // Use the mock server URL
+					ClientID:              "testClientID",
+					ClientSecret:          "testClientSecret",
+					PartialFailurePolicy:  tt.policy,
+					MaxFailedPagesPercent: tt.maxFailedPagesPercent,
 				},
 				httpClient: http.DefaultClient,
 				cache:      cache.New(5*time.Minute, 10*time.Minute),
@@ -246,10 +280,371 @@ func TestSalesforceClient_FetchContentBlocks(t *testing.T) {
 			// check error match
 			tt.wantErr(t, err)
 
-			// check result - not in order hence ElementsMatch
-			require.ElementsMatch(t, got, tt.want)
+			// check result - pages are handed over in page order
+			require.Equal(t, tt.want, withoutRaw(got))
 
 			c.cache.Flush()
 		})
 	}
 }
+
+func TestSalesforceClient_FetchContentBlocksConcurrencyLimit(t *testing.T) {
+	tests := []struct {
+		name               string
+		maxConcurrentPages int
+		totalPages         int
+		failPage           int // 0 means no page fails
+		policy             PartialFailurePolicy
+		wantBlocks         int
+		wantErr            require.ErrorAssertionFunc
+	}{
+		{
+			name:               "Pages fetched with at most 3 workers",
+			maxConcurrentPages: 3,
+			totalPages:         12,
+			wantBlocks:         12,
+			wantErr:            require.NoError,
+		},
+		{
+			name:               "Single worker fetches pages sequentially",
+			maxConcurrentPages: 1,
+			totalPages:         5,
+			wantBlocks:         5,
+			wantErr:            require.NoError,
+		},
+		{
+			name:               "Failing page cancels outstanding pages",
+			maxConcurrentPages: 2,
+			totalPages:         12,
+			failPage:           2,
+			policy:             PartialFailureFailFast,
+			wantErr:            require.Error,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var inFlight, peak, requested atomic.Int32
+			mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var request domain.ContentBlocksRequest
+				json.NewDecoder(r.Body).Decode(&request)
+				requested.Add(1)
+
+				current := inFlight.Add(1)
+				defer inFlight.Add(-1)
+				for {
+					observed := peak.Load()
+					if current <= observed || peak.CompareAndSwap(observed, current) {
+						break
+					}
+				}
+				time.Sleep(10 * time.Millisecond)
+
+				if request.Page.Page == tt.failPage {
+					w.WriteHeader(http.StatusBadRequest)
+					return
+				}
+				json.NewEncoder(w).Encode(ContentAssetsResponse{
+					Count:    tt.totalPages,
+					Page:     request.Page.Page,
+					PageSize: 1,
+					Items:    []domain.Asset{{Content: fmt.Sprintf("Block %d", request.Page.Page)}},
+				})
+			}))
+			defer mockServer.Close()
+
+			c := &client{
+				config: Config{
+					AuthURL:              mockServer.URL,
+					MaxConcurrentPages:   tt.maxConcurrentPages,
+					PartialFailurePolicy: tt.policy,
+				},
+				httpClient: http.DefaultClient,
+				cache:      cache.New(5*time.Minute, 10*time.Minute),
+			}
+			c.cache.Set(cacheKeyAccessTokenKey, "testAccessToken", cache.DefaultExpiration)
+			c.cache.Set(cacheKeyRestInstanceURLKey, mockServer.URL, cache.DefaultExpiration)
+
+			got, err := c.FetchContentBlocks(context.Background(), domain.ContentBlocksRequest{})
+
+			tt.wantErr(t, err)
+			require.Len(t, got, tt.wantBlocks)
+			require.LessOrEqual(t, int(peak.Load()), tt.maxConcurrentPages)
+			if tt.failPage > 0 {
+				// workers stop picking up pages once the failure cancelled the fetch
+				require.Less(t, int(requested.Load()), tt.totalPages)
+			} else {
+				require.Equal(t, tt.totalPages, int(requested.Load()))
+			}
+		})
+	}
+}
+
+func TestSalesforceClient_FetchContentBlocksTokenRefresh(t *testing.T) {
+	tests := []struct {
+		name           string
+		freshToken     string // token accepted by the asset endpoint
+		wantBlocks     int
+		wantTokenCalls int32
+		wantErr        require.ErrorAssertionFunc
+	}{
+		{
+			name:           "Revoked token refreshed once across workers",
+			freshToken:     "freshToken",
+			wantBlocks:     6,
+			wantTokenCalls: 1,
+			wantErr:        require.NoError,
+		},
+		{
+			name:           "Fresh token also rejected",
+			freshToken:     "neverIssuedToken",
+			wantBlocks:     0,
+			wantTokenCalls: 1,
+			wantErr: func(t require.TestingT, err error, _ ...interface{}) {
+				require.ErrorContains(t, err, "unauthorized")
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var tokenCalls atomic.Int32
+			mux := http.NewServeMux()
+			mux.HandleFunc("/v2/token", func(w http.ResponseWriter, r *http.Request) {
+				tokenCalls.Add(1)
+				w.Write([]byte(`{"access_token": "freshToken", "rest_instance_url": "` +
+					"http://" + r.Host + `", "expires_in": 3600}`))
+			})
+			mux.HandleFunc("/asset/v1/content/assets/query", func(w http.ResponseWriter, r *http.Request) {
+				if r.Header.Get("Authorization") != "Bearer "+tt.freshToken {
+					w.WriteHeader(http.StatusUnauthorized)
+					return
+				}
+				var request domain.ContentBlocksRequest
+				json.NewDecoder(r.Body).Decode(&request)
+				json.NewEncoder(w).Encode(ContentAssetsResponse{
+					Count:    6,
+					Page:     request.Page.Page,
+					PageSize: 1,
+					Items:    []domain.Asset{{Content: fmt.Sprintf("Block %d", request.Page.Page)}},
+				})
+			})
+			mockServer := httptest.NewServer(mux)
+			defer mockServer.Close()
+
+			c := &client{
+				config: Config{
+					AuthURL:            mockServer.URL,
+					MaxConcurrentPages: 3,
+				},
+				httpClient: http.DefaultClient,
+				cache:      cache.New(5*time.Minute, 10*time.Minute),
+			}
+			c.cache.Set(cacheKeyAccessTokenKey, "staleToken", cache.DefaultExpiration)
+			c.cache.Set(cacheKeyRestInstanceURLKey, mockServer.URL, cache.DefaultExpiration)
+
+			got, err := c.FetchContentBlocks(context.Background(), domain.ContentBlocksRequest{})
+
+			tt.wantErr(t, err)
+			require.Len(t, got, tt.wantBlocks)
+			require.Equal(t, tt.wantTokenCalls, tokenCalls.Load())
+		})
+	}
+}
+
+func TestSalesforceClient_FetchContentBlocksBusinessUnits(t *testing.T) {
+	var tokenRequests []TokenRequest
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v2/token", func(w http.ResponseWriter, r *http.Request) {
+		var request TokenRequest
+		json.NewDecoder(r.Body).Decode(&request)
+		tokenRequests = append(tokenRequests, request)
+		json.NewEncoder(w).Encode(TokenResponse{
+			AccessToken:     fmt.Sprintf("token-%d", request.AccountID),
+			RestInstanceURL: "http://" + r.Host,
+			ExpiresIn:       3600,
+		})
+	})
+	mux.HandleFunc("/asset/v1/content/assets/query", func(w http.ResponseWriter, r *http.Request) {
+		// each business unit sees its own asset
+		var accountID int
+		fmt.Sscanf(r.Header.Get("Authorization"), "Bearer token-%d", &accountID)
+		json.NewEncoder(w).Encode(ContentAssetsResponse{
+			Count:    1,
+			Page:     1,
+			PageSize: 50,
+			Items:    []domain.Asset{{ID: accountID * 10, Name: fmt.Sprintf("Block of %d", accountID)}},
+		})
+	})
+	mockServer := httptest.NewServer(mux)
+	defer mockServer.Close()
+
+	c := &client{
+		config: Config{
+			AuthURL:    mockServer.URL,
+			AccountIDs: []int{510001, 510002},
+		},
+		httpClient: http.DefaultClient,
+		cache:      cache.New(5*time.Minute, 10*time.Minute),
+	}
+
+	got, err := c.FetchContentBlocks(context.Background(), domain.ContentBlocksRequest{})
+
+	require.NoError(t, err)
+	require.Equal(t, []domain.Asset{
+		{ID: 5100010, Name: "Block of 510001", BusinessUnitID: 510001},
+		{ID: 5100020, Name: "Block of 510002", BusinessUnitID: 510002},
+	}, withoutRaw(got))
+
+	// one token per business unit, cached under its own key
+	require.Len(t, tokenRequests, 2)
+	require.Equal(t, 510001, tokenRequests[0].AccountID)
+	require.Equal(t, 510002, tokenRequests[1].AccountID)
+	cachedToken, found := c.cache.Get(accessTokenCacheKey(510002))
+	require.True(t, found)
+	require.Equal(t, "token-510002", cachedToken)
+	_, found = c.cache.Get(cacheKeyAccessTokenKey)
+	require.False(t, found)
+}
+
+// withoutRaw drops the raw JSON kept by decoding so assets compare by their typed fields
+func TestSalesforceClient_FetchContentBlocksUsesHTTPClient(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v2/token":
+			json.NewEncoder(w).Encode(TokenResponse{
+				AccessToken:     "testAccessToken",
+				ExpiresIn:       3600,
+				RestInstanceURL: "http://" + r.Host,
+			})
+		default:
+			json.NewEncoder(w).Encode(ContentAssetsResponse{
+				Count:    1,
+				Page:     1,
+				PageSize: 1,
+				Items:    []domain.Asset{{Content: "Block"}},
+			})
+		}
+	}))
+	defer mockServer.Close()
+
+	// every SFMC call goes through the transport of the injected client
+	var paths []string
+	httpClient := &http.Client{
+		Transport: transport.RoundTripperFunc(func(request *http.Request) (*http.Response, error) {
+			paths = append(paths, request.URL.Path)
+			return http.DefaultTransport.RoundTrip(request)
+		}),
+	}
+	c := &client{
+		config:     Config{AuthURL: mockServer.URL},
+		httpClient: httpClient,
+		cache:      cache.New(5*time.Minute, 10*time.Minute),
+	}
+
+	got, err := c.FetchContentBlocks(context.Background(), domain.ContentBlocksRequest{})
+
+	require.NoError(t, err)
+	require.Len(t, got, 1)
+	require.Equal(t, []string{"/v2/token", "/asset/v1/content/assets/query"}, paths)
+}
+
+func withoutRaw(assets []domain.Asset) []domain.Asset {
+	if assets == nil {
+		return nil
+	}
+	stripped := make([]domain.Asset, len(assets))
+	for i, asset := range assets {
+		asset.Raw = nil
+		stripped[i] = asset
+	}
+	return stripped
+}
+
+// secondPageFailsHandler serves the first of two pages and fails the second one
+func secondPageFailsHandler(w http.ResponseWriter, r *http.Request) {
+	var request domain.ContentBlocksRequest
+	json.NewDecoder(r.Body).Decode(&request)
+	page := request.Page.Page
+	if page == 1 {
+		response := ContentAssetsResponse{
+			Count:    3,
+			Page:     1,
+			PageSize: 2,
+			Items: []domain.Asset{
+				{Content: "Block 1"},
+				{Content: "Block 2"},
+			},
+		}
+		json.NewEncoder(w).Encode(response)
+	} else if page == 2 {
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+}
+
+func TestSalesforceClient_fetchAccessTokenRetry(t *testing.T) {
+	tests := []struct {
+		name        string
+		statusCodes []int // status codes returned by successive calls, 200 afterwards
+		wantCalls   int
+		wantErr     require.ErrorAssertionFunc
+	}{
+		{
+			name:        "Recovers after throttling and server errors",
+			statusCodes: []int{http.StatusTooManyRequests, http.StatusServiceUnavailable},
+			wantCalls:   3,
+			wantErr:     require.NoError,
+		},
+		{
+			name:        "Bad request is not retried",
+			statusCodes: []int{http.StatusBadRequest},
+			wantCalls:   1,
+			wantErr:     require.Error,
+		},
+		{
+			name:        "Gives up after max attempts",
+			statusCodes: []int{500, 500, 500, 500, 500},
+			wantCalls:   4,
+			wantErr: func(t require.TestingT, err error, _ ...interface{}) {
+				var statusErr *StatusError
+				require.ErrorAs(t, err, &statusErr)
+				require.Equal(t, http.StatusInternalServerError, statusErr.StatusCode)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				calls++
+				if calls <= len(tt.statusCodes) {
+					w.Header().Set("Retry-After", "0")
+					w.WriteHeader(tt.statusCodes[calls-1])
+					return
+				}
+				w.Write([]byte(`{"access_token": "newToken", "rest_instance_url": "newInstanceURL", "expires_in": 3600}`))
+			}))
+			defer server.Close()
+
+			c := &client{
+				config: Config{
+					AuthURL: server.URL,
+					Retry: RetryConfig{
+						MaxAttempts: 4,
+						BaseDelay:   time.Millisecond,
+						MaxDelay:    5 * time.Millisecond,
+						Jitter:      true,
+					},
+				},
+				httpClient: http.DefaultClient,
+				cache:      cache.New(5*time.Minute, 10*time.Minute),
+			}
+
+			_, err := c.fetchAccessToken(context.Background(), defaultAccountID)
+
+			tt.wantErr(t, err)
+			require.Equal(t, tt.wantCalls, calls)
+		})
+	}
+}
@@ -1,7 +1,102 @@
 package salesforce
 
+import (
+	"fmt"
+	"time"
+
+	"jet-example/internal/domain"
+)
+
 type Config struct {
 	AuthURL      string `env:"SALESFORCE_AUTH_URL,notEmpty"`
 	ClientID     string `env:"SALESFORCE_CLIENT_ID,notEmpty"`
 	ClientSecret string `env:"SALESFORCE_CLIENT_SECRET,notEmpty"`
+	// AccountIDs are the MIDs of the business units to fetch, the default business unit when empty
+	AccountIDs   []int `env:"SALESFORCE_ACCOUNT_IDS"`
+	OAuth        OAuthConfig
+	TokenRefresh TokenRefreshConfig
+	Retry        RetryConfig
+	RateLimit    RateLimitConfig
+	Filter       FilterConfig
+	// DataExtensions are read by the client created with NewDataExtensionClient
+	DataExtensions DataExtensionConfig
+	// Publish is read by the uploader created with NewPublisher
+	Publish PublishConfig
+	// ResolveCategoryPaths attaches the Content Builder folder path to each asset
+	ResolveCategoryPaths bool `env:"SALESFORCE_RESOLVE_CATEGORY_PATHS" envDefault:"true"`
+	// ExportEmails also fetches emails and templates when asset types are filtered,
+	// and resolves which blocks their slots embed
+	ExportEmails bool `env:"SALESFORCE_EXPORT_EMAILS" envDefault:"false"`
+	// SortProperty and SortDirection order the assets of each business unit when the
+	// request is not sorted, the asset ID breaks ties so the output order is stable
+	SortProperty  string               `env:"SALESFORCE_SORT_PROPERTY" envDefault:"id"`
+	SortDirection domain.SortDirection `env:"SALESFORCE_SORT_DIRECTION" envDefault:"ASC"`
+	// FileDownloadTimeout bounds the download of a binary asset file,
+	// the timeout of the HTTP client does not apply to files
+	FileDownloadTimeout time.Duration `env:"SALESFORCE_FILE_DOWNLOAD_TIMEOUT" envDefault:"10m"`
+	// MaxConcurrentPages caps the number of asset pages fetched in parallel
+	MaxConcurrentPages int `env:"SALESFORCE_MAX_CONCURRENT_PAGES" envDefault:"10"`
+	// PartialFailurePolicy is one of "fail-fast", "best-effort" or "threshold"
+	PartialFailurePolicy  PartialFailurePolicy `env:"SALESFORCE_PARTIAL_FAILURE_POLICY" envDefault:"fail-fast"`
+	MaxFailedPagesPercent float64              `env:"SALESFORCE_MAX_FAILED_PAGES_PERCENT" envDefault:"0"`
+}
+
+// Validate rejects invalid settings, it is run when the configuration is loaded
+// and again before every fetch
+func (c Config) Validate() error {
+	if err := c.validatePartialFailure(); err != nil {
+		return fmt.Errorf("invalid partial failure configuration: %w", err)
+	}
+
+	if err := c.Filter.validate(); err != nil {
+		return fmt.Errorf("invalid filter configuration: %w", err)
+	}
+
+	if err := c.OAuth.validate(); err != nil {
+		return fmt.Errorf("invalid OAuth configuration: %w", err)
+	}
+
+	if err := c.validatePublish(); err != nil {
+		return fmt.Errorf("invalid publish configuration: %w", err)
+	}
+
+	return nil
+}
+
+// defaultMaxConcurrentPages applies when MaxConcurrentPages is not set
+const defaultMaxConcurrentPages = 10
+
+func (c Config) maxConcurrentPages() int {
+	if c.MaxConcurrentPages <= 0 {
+		return defaultMaxConcurrentPages
+	}
+	return c.MaxConcurrentPages
+}
+
+// defaultFileDownloadTimeout applies when FileDownloadTimeout is not set
+const defaultFileDownloadTimeout = 10 * time.Minute
+
+func (c Config) fileDownloadTimeout() time.Duration {
+	if c.FileDownloadTimeout <= 0 {
+		return defaultFileDownloadTimeout
+	}
+	return c.FileDownloadTimeout
+}
+
+// defaultAccountID stands for the default business unit of the installed package
+const defaultAccountID = 0
+
+func (c Config) accountIDs() []int {
+	if len(c.AccountIDs) == 0 {
+		return []int{defaultAccountID}
+	}
+	return c.AccountIDs
+}
+
+// sortOrder is the sort of the request, or the configured one when the request has none
+func (c Config) sortOrder(sort []domain.Sort) []domain.Sort {
+	if len(sort) > 0 || c.SortProperty == "" {
+		return sort
+	}
+	return []domain.Sort{{Property: c.SortProperty, Direction: c.SortDirection}}
 }
@@ -3,8 +3,8 @@ package salesforce
 import "jet-example/internal/domain"
 
 type ContentAssetsResponse struct {
-	Count    int                   `json:"count"`
-	Page     int                   `json:"page"`
-	PageSize int                   `json:"pageSize"`
-	Items    []domain.ContentBlock `json:"items"`
+	Count    int            `json:"count"`
+	Page     int            `json:"page"`
+	PageSize int            `json:"pageSize"`
+	Items    []domain.Asset `json:"items"`
 }
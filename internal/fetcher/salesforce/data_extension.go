@@ -0,0 +1,203 @@
+package salesforce
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"jet-example/internal/domain"
+)
+
+// DataExtensionAssetType is the asset type of the assets holding Data Extension rows
+const DataExtensionAssetType = "dataextension"
+
+type DataExtensionConfig struct {
+	// Keys are the external keys of the Data Extensions to fetch
+	Keys []string `env:"SALESFORCE_DATA_EXTENSION_KEYS"`
+	// Filters are $filter expressions by Data Extension key e.g. "Translations=Locale eq 'en-US'",
+	// several are separated by semicolons
+	Filters  map[string]string `env:"SALESFORCE_DATA_EXTENSION_FILTERS" envSeparator:";" envKeyValSeparator:"="`
+	PageSize int               `env:"SALESFORCE_DATA_EXTENSION_PAGE_SIZE" envDefault:"2500"`
+}
+
+// defaultDataExtensionPageSize applies when PageSize is not set
+const defaultDataExtensionPageSize = 2500
+
+func (c DataExtensionConfig) pageSize() int {
+	if c.PageSize <= 0 {
+		return defaultDataExtensionPageSize
+	}
+	return c.PageSize
+}
+
+type DataExtensionRowsResponse struct {
+	CustomObjectKey string            `json:"customObjectKey"`
+	Count           int               `json:"count"`
+	Page            int               `json:"page"`
+	PageSize        int               `json:"pageSize"`
+	Items           []json.RawMessage `json:"items"`
+}
+
+// dataExtensionRows is the asset JSON of a Data Extension,
+// each row being the {"keys": ..., "values": ...} object returned by SFMC
+type dataExtensionRows struct {
+	CustomerKey string `json:"customerKey"`
+	Name        string `json:"name"`
+	AssetType   struct {
+		Name string `json:"name"`
+	} `json:"assetType"`
+	Rows []json.RawMessage `json:"rows"`
+}
+
+// dataExtensionClient reads Data Extension rows with the auth, token cache,
+// retries and rate limit of the Salesforce client
+type dataExtensionClient struct {
+	client *client
+}
+
+// NewDataExtensionClient fetches the rows of the Data Extensions configured for a client
+// created with NewSalesforceClient. Every Data Extension is returned as one asset of type
+// DataExtensionAssetType holding its rows, so it can be uploaded like content blocks.
+// Both clients share the token cache, token store and rate limit, each has its own call
+// budget and its own tokens since they need different scopes.
+func NewDataExtensionClient(salesforceClient domain.Fetcher) (domain.Fetcher, error) {
+	source, ok := salesforceClient.(*client)
+	if !ok {
+		return nil, fmt.Errorf("unsupported Salesforce client %T", salesforceClient)
+	}
+
+	return &dataExtensionClient{
+		client: &client{
+			config:         source.config,
+			httpClient:     source.httpClient,
+			cache:          source.cache,
+			tokenStore:     source.tokenStore,
+			session:        source.shared(),
+			budget:         newCallBudget(source.config.RateLimit.CallBudget),
+			requiredScopes: []string{"data_extensions_read"},
+		},
+	}, nil
+}
+
+// StartRun resets the API call budget, every call until the next run counts against it
+func (c *dataExtensionClient) StartRun() {
+	c.client.StartRun()
+}
+
+// FetchContentBlocks fetches every configured Data Extension from every business unit.
+// The query of the request does not apply to rows, filters are configured per Data Extension.
+func (c *dataExtensionClient) FetchContentBlocks(
+	ctx context.Context,
+	request domain.ContentBlocksRequest,
+) ([]domain.Asset, error) {
+	config := c.client.config
+	if len(config.DataExtensions.Keys) == 0 {
+		return nil, errors.New("no Data Extension keys configured")
+	}
+
+	var dataExtensions []domain.Asset
+	for _, accountID := range config.accountIDs() {
+		for _, key := range config.DataExtensions.Keys {
+			rows, err := c.fetchDataExtensionRows(ctx, accountID, key)
+			if err != nil {
+				return nil, c.client.businessUnitError(accountID, fmt.Errorf("failed to fetch Data Extension %q: %w", key, err))
+			}
+
+			asset, err := dataExtensionAsset(key, rows)
+			if err != nil {
+				return nil, err
+			}
+			asset.BusinessUnitID = accountID
+			dataExtensions = append(dataExtensions, asset)
+		}
+	}
+
+	return dataExtensions, nil
+}
+
+// fetchDataExtensionRows fetches the rows of a Data Extension page by page
+func (c *dataExtensionClient) fetchDataExtensionRows(
+	ctx context.Context,
+	accountID int,
+	key string,
+) ([]json.RawMessage, error) {
+	rows := []json.RawMessage{}
+	for page := 1; ; page++ {
+		var response DataExtensionRowsResponse
+		err := c.client.callWithToken(ctx, accountID, func(tokenResponse TokenResponse) (err error) {
+			response, err = c.fetchSingleRowsPage(ctx, tokenResponse, key, page)
+			return err
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch rows page %d: %w", page, err)
+		}
+
+		rows = append(rows, response.Items...)
+		if len(response.Items) == 0 || page*response.PageSize >= response.Count {
+			return rows, nil
+		}
+	}
+}
+
+// fetchSingleRowsPage fetches a single page of rows from the customobjectdata endpoint
+func (c *dataExtensionClient) fetchSingleRowsPage(
+	ctx context.Context,
+	tokenResponse TokenResponse,
+	key string,
+	page int,
+) (DataExtensionRowsResponse, error) {
+	config := c.client.config.DataExtensions
+	query := url.Values{}
+	query.Set("$page", strconv.Itoa(page))
+	query.Set("$pageSize", strconv.Itoa(config.pageSize()))
+	if filter := config.Filters[key]; filter != "" {
+		query.Set("$filter", filter)
+	}
+	rowsURL := tokenResponse.RestInstanceURL +
+		"/data/v1/customobjectdata/key/" + url.PathEscape(key) + "/rowset?" + query.Encode()
+
+	httpRequest, err := http.NewRequestWithContext(ctx, http.MethodGet, rowsURL, nil)
+	if err != nil {
+		return DataExtensionRowsResponse{}, fmt.Errorf("failed to create HTTP request: %w", err)
+	}
+	httpRequest.Header.Set("Authorization", "Bearer "+tokenResponse.AccessToken)
+
+	httpResponse, err := c.client.httpClient.Do(httpRequest)
+	if err != nil {
+		return DataExtensionRowsResponse{}, fmt.Errorf("failed to perform HTTP request: %w", err)
+	}
+	defer httpResponse.Body.Close()
+
+	if httpResponse.StatusCode != http.StatusOK {
+		return DataExtensionRowsResponse{}, newStatusError("fetch Data Extension rows", httpResponse)
+	}
+
+	var response DataExtensionRowsResponse
+	if err := json.NewDecoder(httpResponse.Body).Decode(&response); err != nil {
+		return DataExtensionRowsResponse{}, fmt.Errorf("failed to decode response body: %w", err)
+	}
+
+	return response, nil
+}
+
+// dataExtensionAsset wraps the rows of a Data Extension into an asset, Raw holds
+// the whole asset JSON so the rows are written out as they were received
+func dataExtensionAsset(key string, rows []json.RawMessage) (domain.Asset, error) {
+	snapshot := dataExtensionRows{CustomerKey: key, Name: key, Rows: rows}
+	snapshot.AssetType.Name = DataExtensionAssetType
+	raw, err := json.Marshal(snapshot)
+	if err != nil {
+		return domain.Asset{}, fmt.Errorf("failed to encode rows of Data Extension %q: %w", key, err)
+	}
+
+	return domain.Asset{
+		CustomerKey: key,
+		Name:        key,
+		AssetType:   domain.AssetType{Name: DataExtensionAssetType},
+		Raw:         raw,
+	}, nil
+}
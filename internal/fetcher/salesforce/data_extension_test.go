@@ -0,0 +1,92 @@
+package salesforce
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/patrickmn/go-cache"
+	"github.com/stretchr/testify/require"
+
+	"jet-example/internal/domain"
+)
+
+func TestDataExtensionClient_FetchContentBlocks(t *testing.T) {
+	var filters []string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/data/v1/customobjectdata/key/{key}/rowset", func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "Bearer testAccessToken", r.Header.Get("Authorization"))
+		filters = append(filters, r.URL.Query().Get("$filter"))
+		page, _ := strconv.Atoi(r.URL.Query().Get("$page"))
+		pageSize, _ := strconv.Atoi(r.URL.Query().Get("$pageSize"))
+
+		// three rows served two by two
+		var items []json.RawMessage
+		for row := (page-1)*pageSize + 1; row <= min(page*pageSize, 3); row++ {
+			items = append(items, json.RawMessage(fmt.Sprintf(
+				`{"keys":{"id":"%d"},"values":{"key":"%s"}}`, row, r.PathValue("key"),
+			)))
+		}
+		json.NewEncoder(w).Encode(DataExtensionRowsResponse{
+			CustomObjectKey: r.PathValue("key"),
+			Count:           3,
+			Page:            page,
+			PageSize:        pageSize,
+			Items:           items,
+		})
+	})
+	mockServer := httptest.NewServer(mux)
+	defer mockServer.Close()
+
+	sharedCache := cache.New(5*time.Minute, 10*time.Minute)
+	salesforceClient := NewSalesforceClient(
+		Config{
+			AuthURL: mockServer.URL,
+			DataExtensions: DataExtensionConfig{
+				Keys:     []string{"Translations", "Products"},
+				Filters:  map[string]string{"Translations": "Locale eq 'en-US'"},
+				PageSize: 2,
+			},
+		},
+		http.DefaultClient,
+		sharedCache,
+		nil,
+	)
+	fetcher, err := NewDataExtensionClient(salesforceClient)
+	require.NoError(t, err)
+
+	// the token of the Salesforce client lacks the Data Extension scope, only the
+	// one cached for the Data Extension scope is used and the auth endpoint is never called
+	dataExtensionClient := fetcher.(*dataExtensionClient).client
+	sharedCache.Set(cacheKeyAccessTokenKey, "contentAccessToken", cache.DefaultExpiration)
+	sharedCache.Set(cacheKeyRestInstanceURLKey, mockServer.URL, cache.DefaultExpiration)
+	sharedCache.Set(dataExtensionClient.tokenKey(cacheKeyAccessTokenKey), "testAccessToken", cache.DefaultExpiration)
+	sharedCache.Set(dataExtensionClient.tokenKey(cacheKeyRestInstanceURLKey), mockServer.URL, cache.DefaultExpiration)
+	require.Same(t, salesforceClient.(*client).shared(), dataExtensionClient.shared())
+
+	got, err := fetcher.FetchContentBlocks(context.Background(), domain.ContentBlocksRequest{})
+
+	require.NoError(t, err)
+	require.Len(t, got, 2)
+	require.Equal(t, []string{"Locale eq 'en-US'", "Locale eq 'en-US'", "", ""}, filters)
+	require.Equal(t, "Translations", got[0].CustomerKey)
+	require.Equal(t, DataExtensionAssetType, got[0].AssetType.Name)
+
+	data, err := json.Marshal(got[0])
+	require.NoError(t, err)
+	var snapshot dataExtensionRows
+	require.NoError(t, json.Unmarshal(data, &snapshot))
+	require.Len(t, snapshot.Rows, 3)
+	require.Equal(t, "Translations", snapshot.Name)
+	require.Equal(t, DataExtensionAssetType, snapshot.AssetType.Name)
+	require.JSONEq(t, `{"keys":{"id":"3"},"values":{"key":"Translations"}}`, string(snapshot.Rows[2]))
+
+	// a Data Extension client never streams content blocks
+	_, streams := fetcher.(domain.StreamingFetcher)
+	require.False(t, streams)
+}
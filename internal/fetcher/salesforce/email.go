@@ -0,0 +1,23 @@
+package salesforce
+
+import (
+	"slices"
+
+	"jet-example/internal/domain"
+)
+
+// emailAssetTypes are the asset types whose slots embed content blocks
+var emailAssetTypes = []string{"templatebasedemail", "htmlemail", "textonlyemail", "template"}
+
+// attachCompositions resolves the slot tree of the emails and templates among assets
+func (c *client) attachCompositions(assets []domain.Asset) {
+	if !c.config.ExportEmails {
+		return
+	}
+
+	for i, asset := range assets {
+		if slices.Contains(emailAssetTypes, asset.AssetType.Name) {
+			assets[i].Composition = domain.ResolveComposition(asset)
+		}
+	}
+}
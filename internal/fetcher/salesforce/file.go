@@ -0,0 +1,162 @@
+package salesforce
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"jet-example/internal/domain"
+)
+
+// OpenAssetFile streams the file of a binary asset from the asset file endpoint,
+// falling back to the published URL when the endpoint does not know the file.
+// The download is bounded by Config.FileDownloadTimeout rather than the timeout
+// of the HTTP client. The returned reader implements domain.SizedReader.
+func (c *client) OpenAssetFile(ctx context.Context, asset domain.Asset) (io.ReadCloser, error) {
+	if asset.FileProperties == nil {
+		return nil, fmt.Errorf("asset %d has no file", asset.ID)
+	}
+
+	// released when the file is closed
+	ctx, cancel := context.WithTimeout(ctx, c.config.fileDownloadTimeout())
+
+	var file *assetFile
+	err := c.callWithToken(ctx, asset.BusinessUnitID, func(tokenResponse TokenResponse) (err error) {
+		file, err = c.openAssetFileEndpoint(ctx, tokenResponse, asset.ID)
+		return err
+	})
+	if isNotFound(err) && asset.FileProperties.PublishedURL != "" {
+		err = c.withRetry(ctx, func() (err error) {
+			file, err = c.openPublishedURL(ctx, asset.FileProperties.PublishedURL)
+			return err
+		})
+	}
+	if err != nil {
+		cancel()
+		return nil, fmt.Errorf("failed to download file of asset %d: %w", asset.ID, err)
+	}
+
+	file.cancel = cancel
+	return file, nil
+}
+
+// fileHTTPClient is the HTTP client without its overall timeout,
+// which covers reading the body and would cut large files short
+func (c *client) fileHTTPClient() *http.Client {
+	httpClient := *c.httpClient
+	httpClient.Timeout = 0
+	return &httpClient
+}
+
+// openAssetFileEndpoint opens the base64 encoded file served by the asset file endpoint
+// and decodes it while it is read
+func (c *client) openAssetFileEndpoint(
+	ctx context.Context,
+	tokenResponse TokenResponse,
+	assetID int,
+) (*assetFile, error) {
+	fileURL := tokenResponse.RestInstanceURL + "/asset/v1/content/assets/" + strconv.Itoa(assetID) + "/file"
+
+	httpRequest, err := http.NewRequestWithContext(ctx, http.MethodGet, fileURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create HTTP request: %w", err)
+	}
+	httpRequest.Header.Set("Authorization", "Bearer "+tokenResponse.AccessToken)
+
+	httpResponse, err := c.fileHTTPClient().Do(httpRequest)
+	if err != nil {
+		return nil, fmt.Errorf("failed to perform HTTP request: %w", err)
+	}
+
+	if httpResponse.StatusCode != http.StatusOK {
+		httpResponse.Body.Close()
+		return nil, newStatusError("fetch asset file", httpResponse)
+	}
+
+	// the length of the base64 body does not tell the size of the decoded file
+	return &assetFile{
+		Reader: base64.NewDecoder(base64.StdEncoding, &unquoteReader{reader: bufio.NewReader(httpResponse.Body)}),
+		body:   httpResponse.Body,
+		size:   -1,
+	}, nil
+}
+
+// openPublishedURL opens the raw file published on the SFMC content delivery network
+func (c *client) openPublishedURL(ctx context.Context, publishedURL string) (*assetFile, error) {
+	httpRequest, err := http.NewRequestWithContext(ctx, http.MethodGet, publishedURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create HTTP request: %w", err)
+	}
+
+	httpResponse, err := c.fileHTTPClient().Do(httpRequest)
+	if err != nil {
+		return nil, fmt.Errorf("failed to perform HTTP request: %w", err)
+	}
+
+	if httpResponse.StatusCode != http.StatusOK {
+		httpResponse.Body.Close()
+		return nil, newStatusError("fetch published file", httpResponse)
+	}
+
+	// ContentLength is -1 when unknown, e.g. for chunked or compressed responses
+	return &assetFile{
+		Reader: httpResponse.Body,
+		body:   httpResponse.Body,
+		size:   httpResponse.ContentLength,
+	}, nil
+}
+
+// isNotFound reports whether SFMC answered with 404
+func isNotFound(err error) bool {
+	var statusErr *StatusError
+	return errors.As(err, &statusErr) && statusErr.StatusCode == http.StatusNotFound
+}
+
+// assetFile is a downloaded file, size is the length confirmed by the response or -1
+type assetFile struct {
+	io.Reader
+	body   io.Closer
+	size   int64
+	cancel context.CancelFunc
+}
+
+func (f *assetFile) Size() int64 {
+	return f.size
+}
+
+// Close closes the response body and releases the download timeout
+func (f *assetFile) Close() error {
+	if f.cancel != nil {
+		defer f.cancel()
+	}
+	return f.body.Close()
+}
+
+// unquoteReader drops the double quotes around a base64 body served as a JSON string
+type unquoteReader struct {
+	reader *bufio.Reader
+}
+
+func (r *unquoteReader) Read(p []byte) (int, error) {
+	n := 0
+	for n < len(p) {
+		b, err := r.reader.ReadByte()
+		if err != nil {
+			return n, err
+		}
+		if b == '"' {
+			continue
+		}
+		p[n] = b
+		n++
+		if r.reader.Buffered() == 0 {
+			break // do not block for more data than already received
+		}
+	}
+	return n, nil
+}
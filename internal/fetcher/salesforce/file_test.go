@@ -0,0 +1,131 @@
+package salesforce
+
+import (
+	"context"
+	"encoding/base64"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/patrickmn/go-cache"
+	"github.com/stretchr/testify/require"
+
+	"jet-example/internal/domain"
+)
+
+func TestSalesforceClient_OpenAssetFile(t *testing.T) {
+	content := strings.Repeat("binary\x00content", 1000)
+	encoded := base64.StdEncoding.EncodeToString([]byte(content))
+
+	tests := []struct {
+		name        string
+		fileHandler func(w http.ResponseWriter, r *http.Request)
+		asset       domain.Asset
+		want        string
+		wantSize    int64
+		wantErr     require.ErrorAssertionFunc
+	}{
+		{
+			name: "Base64 body decoded",
+			fileHandler: func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(encoded))
+			},
+			asset:    domain.Asset{ID: 7, FileProperties: &domain.FileProperties{FileName: "logo.png"}},
+			want:     content,
+			wantSize: -1,
+			wantErr:  require.NoError,
+		},
+		{
+			name: "Base64 body served as JSON string",
+			fileHandler: func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(`"` + encoded + `"`))
+			},
+			asset:    domain.Asset{ID: 7, FileProperties: &domain.FileProperties{FileName: "logo.png"}},
+			want:     content,
+			wantSize: -1,
+			wantErr:  require.NoError,
+		},
+		{
+			name: "Falls back to published URL",
+			fileHandler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusNotFound)
+			},
+			asset: domain.Asset{ID: 7, FileProperties: &domain.FileProperties{
+				PublishedURL: "/published/logo.png",
+				FileSize:     1, // outdated metadata
+			}},
+			want:     content,
+			wantSize: int64(len(content)),
+			wantErr:  require.NoError,
+		},
+		{
+			name:    "Asset without file",
+			asset:   domain.Asset{ID: 7},
+			wantErr: require.Error,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mux := http.NewServeMux()
+			mux.HandleFunc("/asset/v1/content/assets/7/file", func(w http.ResponseWriter, r *http.Request) {
+				require.Equal(t, "Bearer testAccessToken", r.Header.Get("Authorization"))
+				tt.fileHandler(w, r)
+			})
+			mux.HandleFunc("/published/logo.png", func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Length", strconv.Itoa(len(content)))
+				w.Write([]byte(content))
+			})
+			mockServer := httptest.NewServer(mux)
+			defer mockServer.Close()
+
+			if tt.asset.FileProperties != nil && tt.asset.FileProperties.PublishedURL != "" {
+				tt.asset.FileProperties.PublishedURL = mockServer.URL + tt.asset.FileProperties.PublishedURL
+			}
+
+			c := &client{
+				config:     Config{AuthURL: mockServer.URL},
+				httpClient: http.DefaultClient,
+				cache:      cache.New(5*time.Minute, 10*time.Minute),
+			}
+			c.cache.Set(cacheKeyAccessTokenKey, "testAccessToken", cache.DefaultExpiration)
+			c.cache.Set(cacheKeyRestInstanceURLKey, mockServer.URL, cache.DefaultExpiration)
+
+			file, err := c.OpenAssetFile(context.Background(), tt.asset)
+			tt.wantErr(t, err)
+			if err != nil {
+				return
+			}
+			defer file.Close()
+
+			got, err := io.ReadAll(file)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, string(got))
+			require.Equal(t, tt.wantSize, file.(domain.SizedReader).Size())
+		})
+	}
+}
+
+func TestSalesforceClient_OpenAssetFileWithoutClientTimeout(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("large"))
+		w.(http.Flusher).Flush()
+		time.Sleep(50 * time.Millisecond) // a download outlasting the client timeout
+		w.Write([]byte(" file"))
+	}))
+	defer mockServer.Close()
+
+	c := &client{httpClient: &http.Client{Timeout: 20 * time.Millisecond}}
+
+	file, err := c.openPublishedURL(context.Background(), mockServer.URL)
+	require.NoError(t, err)
+	defer file.Close()
+
+	got, err := io.ReadAll(file)
+	require.NoError(t, err)
+	require.Equal(t, "large file", string(got))
+}
@@ -0,0 +1,137 @@
+package salesforce
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"slices"
+	"strconv"
+
+	"jet-example/internal/domain"
+)
+
+// FilterConfig narrows the assets fetched from Content Builder.
+// Asset types and categories are sent as part of the asset query,
+// name patterns are matched against fetched assets.
+type FilterConfig struct {
+	// IncludeAssetTypes and ExcludeAssetTypes take asset type names (e.g. htmlblock) or numeric IDs
+	IncludeAssetTypes []string `env:"SALESFORCE_INCLUDE_ASSET_TYPES"`
+	ExcludeAssetTypes []string `env:"SALESFORCE_EXCLUDE_ASSET_TYPES"`
+	CategoryIDs       []int    `env:"SALESFORCE_CATEGORY_IDS"`
+	// RecursiveCategories also includes every subcategory of CategoryIDs
+	RecursiveCategories bool `env:"SALESFORCE_CATEGORY_RECURSIVE" envDefault:"false"`
+	// IncludeNamePatterns and ExcludeNamePatterns are glob patterns, see path.Match
+	IncludeNamePatterns []string `env:"SALESFORCE_INCLUDE_NAME_PATTERNS"`
+	ExcludeNamePatterns []string `env:"SALESFORCE_EXCLUDE_NAME_PATTERNS"`
+}
+
+func (f FilterConfig) validate() error {
+	for _, pattern := range slices.Concat(f.IncludeNamePatterns, f.ExcludeNamePatterns) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid name pattern %q: %w", pattern, err)
+		}
+	}
+	for _, assetType := range slices.Concat(f.IncludeAssetTypes, f.ExcludeAssetTypes) {
+		if assetType == "" {
+			return errors.New("empty asset type")
+		}
+	}
+	return nil
+}
+
+// query translates asset type and category filters into an asset query,
+// it returns nil when there is nothing to filter on
+func (f FilterConfig) query(categoryIDs []int) *domain.Query {
+	var includeTypes *domain.Query
+	names, ids := splitAssetTypes(f.IncludeAssetTypes)
+	if len(names) > 0 {
+		includeTypes = domain.In("assetType.name", names...)
+	}
+	if len(ids) > 0 {
+		includeTypes = domain.Or(includeTypes, domain.In("assetType.id", ids...))
+	}
+
+	// the asset query has no "not in" operator, so exclusions are chained
+	var excludeTypes []*domain.Query
+	names, ids = splitAssetTypes(f.ExcludeAssetTypes)
+	for _, name := range names {
+		excludeTypes = append(excludeTypes, domain.NotEqual("assetType.name", name))
+	}
+	for _, id := range ids {
+		excludeTypes = append(excludeTypes, domain.NotEqual("assetType.id", id))
+	}
+
+	var categories *domain.Query
+	if len(categoryIDs) > 0 {
+		categories = domain.In("category.id", categoryIDs...)
+	}
+
+	return domain.And(includeTypes, domain.And(excludeTypes...), categories)
+}
+
+// matchesName applies the name patterns, which the asset query cannot express
+func (f FilterConfig) matchesName(name string) bool {
+	for _, pattern := range f.ExcludeNamePatterns {
+		if matched, _ := path.Match(pattern, name); matched {
+			return false
+		}
+	}
+	if len(f.IncludeNamePatterns) == 0 {
+		return true
+	}
+	for _, pattern := range f.IncludeNamePatterns {
+		if matched, _ := path.Match(pattern, name); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// filterByName drops the assets whose name does not match the name patterns
+func (f FilterConfig) filterByName(assets []domain.Asset) []domain.Asset {
+	if len(f.IncludeNamePatterns) == 0 && len(f.ExcludeNamePatterns) == 0 {
+		return assets
+	}
+
+	filtered := assets[:0]
+	for _, asset := range assets {
+		if f.matchesName(asset.Name) {
+			filtered = append(filtered, asset)
+		}
+	}
+	return filtered
+}
+
+// splitAssetTypes separates asset type names from numeric asset type IDs
+func splitAssetTypes(assetTypes []string) (names []string, ids []int) {
+	for _, assetType := range assetTypes {
+		if id, err := strconv.Atoi(assetType); err == nil {
+			ids = append(ids, id)
+			continue
+		}
+		names = append(names, assetType)
+	}
+	return names, ids
+}
+
+// filterQuery builds the server-side filter, resolving subcategories from the category tree
+// which is only needed when categories are filtered recursively
+func (c *client) filterQuery(tree *categoryTree) *domain.Query {
+	categoryIDs := c.config.Filter.CategoryIDs
+	if c.config.Filter.RecursiveCategories && tree != nil {
+		categoryIDs = tree.descendantIDs(categoryIDs)
+	}
+
+	filter := c.config.Filter
+	if c.config.ExportEmails && len(filter.IncludeAssetTypes) > 0 {
+		filter.IncludeAssetTypes = slices.Concat(filter.IncludeAssetTypes, emailAssetTypes)
+	}
+
+	return filter.query(categoryIDs)
+}
+
+// needsCategories reports whether a run has to fetch the category tree
+func (c *client) needsCategories() bool {
+	return c.config.ResolveCategoryPaths ||
+		(c.config.Filter.RecursiveCategories && len(c.config.Filter.CategoryIDs) > 0)
+}
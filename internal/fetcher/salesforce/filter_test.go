@@ -0,0 +1,206 @@
+package salesforce
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/patrickmn/go-cache"
+	"github.com/stretchr/testify/require"
+
+	"jet-example/internal/domain"
+)
+
+func TestFilterConfig_query(t *testing.T) {
+	tests := []struct {
+		name        string
+		filter      FilterConfig
+		categoryIDs []int
+		want        *domain.Query
+	}{
+		{
+			name:   "No filter",
+			filter: FilterConfig{},
+			want:   nil,
+		},
+		{
+			name:   "Included asset types by name and ID",
+			filter: FilterConfig{IncludeAssetTypes: []string{"htmlblock", "textblock", "220"}},
+			want: domain.Or(
+				domain.In("assetType.name", "htmlblock", "textblock"),
+				domain.In("assetType.id", 220),
+			),
+		},
+		{
+			name: "Excluded asset types and categories",
+			filter: FilterConfig{
+				ExcludeAssetTypes: []string{"freeformblock", "195"},
+			},
+			categoryIDs: []int{10, 11},
+			want: domain.And(
+				domain.NotEqual("assetType.name", "freeformblock"),
+				domain.NotEqual("assetType.id", 195),
+				domain.In("category.id", 10, 11),
+			),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.filter.query(tt.categoryIDs)
+			require.Equal(t, tt.want, got)
+			if got != nil {
+				require.NoError(t, got.Validate())
+			}
+		})
+	}
+}
+
+func TestFilterConfig_matchesName(t *testing.T) {
+	filter := FilterConfig{
+		IncludeNamePatterns: []string{"Footer*", "Header - *"},
+		ExcludeNamePatterns: []string{"*draft*"},
+	}
+
+	require.True(t, filter.matchesName("Footer EN"))
+	require.True(t, filter.matchesName("Header - DE"))
+	require.False(t, filter.matchesName("Footer draft"))
+	require.False(t, filter.matchesName("Banner"))
+	require.True(t, FilterConfig{}.matchesName("Banner"))
+	require.Error(t, FilterConfig{IncludeNamePatterns: []string{"[a-"}}.validate())
+}
+
+func TestSalesforceClient_FetchContentBlocksFiltered(t *testing.T) {
+	var sentQuery *domain.Query
+	mux := http.NewServeMux()
+	mux.HandleFunc("/asset/v1/content/categories", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(CategoriesResponse{
+			Count:    4,
+			Page:     1,
+			PageSize: categoriesPageSize,
+			Items: []domain.Category{
+				{ID: 1, Name: "Content Builder"},
+				{ID: 2, Name: "Newsletters", ParentID: 1},
+				{ID: 3, Name: "Footers", ParentID: 2},
+				{ID: 4, Name: "Archive", ParentID: 1},
+			},
+		})
+	})
+	mux.HandleFunc("/asset/v1/content/assets/query", func(w http.ResponseWriter, r *http.Request) {
+		var request domain.ContentBlocksRequest
+		json.NewDecoder(r.Body).Decode(&request)
+		sentQuery = request.Query
+		json.NewEncoder(w).Encode(ContentAssetsResponse{
+			Count:    3,
+			Page:     1,
+			PageSize: 50,
+			Items: []domain.Asset{
+				{ID: 1, Name: "Footer EN", Category: domain.Category{ID: 3}},
+				{ID: 2, Name: "Footer draft"},
+				{ID: 3, Name: "Banner"},
+			},
+		})
+	})
+	mockServer := httptest.NewServer(mux)
+	defer mockServer.Close()
+
+	c := &client{
+		config: Config{
+			AuthURL: mockServer.URL,
+			Filter: FilterConfig{
+				IncludeAssetTypes:   []string{"htmlblock"},
+				CategoryIDs:         []int{2},
+				RecursiveCategories: true,
+				IncludeNamePatterns: []string{"Footer*"},
+				ExcludeNamePatterns: []string{"*draft*"},
+			},
+			ResolveCategoryPaths: true,
+		},
+		httpClient: http.DefaultClient,
+		cache:      cache.New(5*time.Minute, 10*time.Minute),
+	}
+	c.cache.Set(cacheKeyAccessTokenKey, "testAccessToken", cache.DefaultExpiration)
+	c.cache.Set(cacheKeyRestInstanceURLKey, mockServer.URL, cache.DefaultExpiration)
+
+	got, err := c.FetchContentBlocks(context.Background(), domain.ContentBlocksRequest{})
+
+	require.NoError(t, err)
+	require.Len(t, got, 1)
+	require.Equal(t, "Footer EN", got[0].Name)
+	require.Equal(t, "Content Builder/Newsletters/Footers", got[0].Category.Path)
+
+	wantQuery, err := json.Marshal(domain.And(
+		domain.In("assetType.name", "htmlblock"),
+		domain.In("category.id", 2, 3),
+	))
+	require.NoError(t, err)
+	gotQuery, err := json.Marshal(sentQuery)
+	require.NoError(t, err)
+	require.JSONEq(t, string(wantQuery), string(gotQuery))
+}
+
+func TestSalesforceClient_FetchContentBlocksCategoriesPerRun(t *testing.T) {
+	var categoryRequests atomic.Int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("/asset/v1/content/categories", func(w http.ResponseWriter, r *http.Request) {
+		categoryRequests.Add(1)
+		json.NewEncoder(w).Encode(CategoriesResponse{
+			Count:    1,
+			Page:     1,
+			PageSize: categoriesPageSize,
+			Items:    []domain.Category{{ID: 1, Name: "Content Builder"}},
+		})
+	})
+	mux.HandleFunc("/asset/v1/content/assets/query", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(ContentAssetsResponse{
+			Count:    1,
+			Page:     1,
+			PageSize: 50,
+			Items:    []domain.Asset{{ID: 1, Category: domain.Category{ID: 1}}},
+		})
+	})
+	mockServer := httptest.NewServer(mux)
+	defer mockServer.Close()
+
+	c := &client{
+		config:     Config{AuthURL: mockServer.URL, ResolveCategoryPaths: true},
+		httpClient: http.DefaultClient,
+		cache:      cache.New(5*time.Minute, 10*time.Minute),
+	}
+	c.cache.Set(cacheKeyAccessTokenKey, "testAccessToken", cache.DefaultExpiration)
+	c.cache.Set(cacheKeyRestInstanceURLKey, mockServer.URL, cache.DefaultExpiration)
+
+	// the tree is fetched once per run, however often the blocks are fetched
+	c.StartRun()
+	for range 2 {
+		got, err := c.FetchContentBlocks(context.Background(), domain.ContentBlocksRequest{})
+		require.NoError(t, err)
+		require.Equal(t, "Content Builder", got[0].Category.Path)
+	}
+	require.Equal(t, int32(1), categoryRequests.Load())
+
+	// the next run fetches it again
+	c.StartRun()
+	_, err := c.FetchContentBlocks(context.Background(), domain.ContentBlocksRequest{})
+	require.NoError(t, err)
+	require.Equal(t, int32(2), categoryRequests.Load())
+}
+
+func TestSalesforceClient_filterQueryExportEmails(t *testing.T) {
+	c := &client{config: Config{
+		Filter:       FilterConfig{IncludeAssetTypes: []string{"htmlblock"}},
+		ExportEmails: true,
+	}}
+
+	want := domain.In("assetType.name", slices.Concat([]string{"htmlblock"}, emailAssetTypes)...)
+	require.Equal(t, want, c.filterQuery(nil))
+
+	// without asset type filter every asset, emails included, is fetched already
+	c.config.Filter.IncludeAssetTypes = nil
+	require.Nil(t, c.filterQuery(nil))
+}
@@ -0,0 +1,136 @@
+package salesforce
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/patrickmn/go-cache"
+
+	"jet-example/internal/domain"
+)
+
+// ErrScopeNotGranted is returned when a token lacks a scope the client needs
+var ErrScopeNotGranted = errors.New("scope not granted")
+
+// GrantType is the OAuth grant the access tokens are requested with
+type GrantType string
+
+const (
+	// GrantClientCredentials is used by server-to-server installed packages
+	GrantClientCredentials GrantType = "client_credentials"
+	// GrantRefreshToken is used by web app installed packages, every token request
+	// exchanges the refresh token for an access token and a new refresh token
+	GrantRefreshToken GrantType = "refresh_token"
+)
+
+type OAuthConfig struct {
+	GrantType GrantType `env:"SALESFORCE_GRANT_TYPE" envDefault:"client_credentials"`
+	// Scopes restrict the tokens to the listed permissions e.g. "saved_content_read,documents_and_images_read",
+	// the permissions of the installed package apply when empty
+	Scopes []string `env:"SALESFORCE_SCOPES"`
+	// RefreshToken is the first refresh token of the refresh_token grant, shared by every
+	// business unit. The rotated ones are kept in the token store and take precedence.
+	RefreshToken string `env:"SALESFORCE_REFRESH_TOKEN"`
+	// AuthorizationCode and RedirectURI are exchanged for the first refresh token
+	// when no refresh token is known yet, a code can only be used once
+	AuthorizationCode string `env:"SALESFORCE_AUTHORIZATION_CODE"`
+	RedirectURI       string `env:"SALESFORCE_REDIRECT_URI"`
+}
+
+// defaultRequiredScopes is what fetching Content Builder assets needs
+var defaultRequiredScopes = []string{"saved_content_read"}
+
+func (c OAuthConfig) validate() error {
+	switch c.GrantType {
+	case "", GrantClientCredentials:
+		return nil
+	case GrantRefreshToken:
+		if c.RefreshToken == "" && (c.AuthorizationCode == "" || c.RedirectURI == "") {
+			return errors.New("the refresh_token grant needs a refresh token, or an authorization code and its redirect URI")
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown grant type %q", c.GrantType)
+	}
+}
+
+// tokenRequest builds the token request of the configured grant for a business unit
+func (c *client) tokenRequest(ctx context.Context, accountID int) (TokenRequest, error) {
+	oauth := c.config.OAuth
+	request := TokenRequest{
+		GrantType:    string(GrantClientCredentials), // since this is server-to-server integration (according to docs)
+		ClientID:     c.config.ClientID,
+		ClientSecret: c.config.ClientSecret,
+		AccountID:    accountID,
+		Scope:        strings.Join(oauth.Scopes, " "),
+	}
+	if oauth.GrantType != GrantRefreshToken {
+		return request, nil
+	}
+
+	if refreshToken := c.refreshToken(ctx); refreshToken != "" {
+		request.GrantType = string(GrantRefreshToken)
+		request.RefreshToken = refreshToken
+		return request, nil
+	}
+	if oauth.AuthorizationCode == "" {
+		return TokenRequest{}, errors.New("no refresh token or authorization code to request a token with")
+	}
+	request.GrantType = "authorization_code"
+	request.Code = oauth.AuthorizationCode
+	request.RedirectURI = oauth.RedirectURI
+	return request, nil
+}
+
+// refreshToken returns the latest refresh token: the newest of the one rotated by this
+// process and the one in the token store, which another process may have rotated since,
+// then the configured one
+func (c *client) refreshToken(ctx context.Context) string {
+	latest, _ := c.cache.Get(c.refreshTokenKey())
+	rotated, _ := latest.(domain.AccessToken)
+	stored := c.getStoredRefreshToken(ctx)
+	if stored.RefreshToken != "" && (rotated.RefreshToken == "" || stored.IssuedAt.After(rotated.IssuedAt)) {
+		rotated = stored
+	}
+	if rotated.RefreshToken != "" {
+		return rotated.RefreshToken
+	}
+	return c.config.OAuth.RefreshToken
+}
+
+// keepRefreshToken remembers a rotated refresh token in the process cache and shares it
+// through the token store, the previous one is no longer valid
+func (c *client) keepRefreshToken(ctx context.Context, refreshToken string) {
+	if refreshToken == "" {
+		return
+	}
+
+	rotated := domain.AccessToken{RefreshToken: refreshToken, IssuedAt: time.Now()}
+	c.cache.Set(c.refreshTokenKey(), rotated, cache.NoExpiration)
+	c.saveStoredRefreshToken(ctx, rotated)
+}
+
+// checkScopes verifies that the granted scopes cover the requested ones and the ones
+// the client needs. Tokens reporting no scope at all are not checked.
+func (c *client) checkScopes(tokenResponse TokenResponse) error {
+	if tokenResponse.Scope == "" {
+		return nil
+	}
+
+	granted := strings.Fields(tokenResponse.Scope)
+	var missing []string
+	for _, scope := range slices.Concat(c.config.OAuth.Scopes, c.requiredScopes) {
+		if !slices.Contains(granted, scope) && !slices.Contains(missing, scope) {
+			missing = append(missing, scope)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("%w: %s", ErrScopeNotGranted, strings.Join(missing, ", "))
+	}
+
+	return nil
+}
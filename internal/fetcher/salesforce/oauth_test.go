@@ -0,0 +1,179 @@
+package salesforce
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/patrickmn/go-cache"
+	"github.com/stretchr/testify/require"
+
+	"jet-example/internal/domain"
+)
+
+func TestSalesforceClient_fetchAccessTokenRefreshTokenGrant(t *testing.T) {
+	var requests []TokenRequest
+	grantedScope := "saved_content_read documents_and_images_read"
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request TokenRequest
+		json.NewDecoder(r.Body).Decode(&request)
+		requests = append(requests, request)
+
+		// every use rotates the refresh token
+		json.NewEncoder(w).Encode(TokenResponse{
+			AccessToken:     "token" + strconv.Itoa(len(requests)),
+			ExpiresIn:       1200,
+			RestInstanceURL: "instanceURL",
+			Scope:           grantedScope,
+			RefreshToken:    "refresh" + strconv.Itoa(len(requests)),
+		})
+	}))
+	defer mockServer.Close()
+
+	config := Config{
+		AuthURL:      mockServer.URL,
+		ClientID:     "testClientID",
+		ClientSecret: "testClientSecret",
+		OAuth: OAuthConfig{
+			GrantType:         GrantRefreshToken,
+			Scopes:            []string{"saved_content_read", "documents_and_images_read"},
+			AuthorizationCode: "code",
+			RedirectURI:       "https://example.com/callback",
+		},
+	}
+	store := &fakeTokenStore{tokens: map[string]domain.AccessToken{}}
+	newClient := func() *client {
+		return &client{
+			config:         config,
+			httpClient:     http.DefaultClient,
+			cache:          cache.New(5*time.Minute, 10*time.Minute),
+			tokenStore:     store,
+			requiredScopes: defaultRequiredScopes,
+		}
+	}
+	ctx := context.Background()
+
+	const refreshTokenKey = "testClientID:refreshToken"
+
+	// the first run exchanges the authorization code
+	_, err := newClient().fetchAccessToken(ctx, defaultAccountID)
+	require.NoError(t, err)
+	require.Equal(t, "authorization_code", requests[0].GrantType)
+	require.Equal(t, "code", requests[0].Code)
+	require.Equal(t, "https://example.com/callback", requests[0].RedirectURI)
+	require.Equal(t, "saved_content_read documents_and_images_read", requests[0].Scope)
+	require.Equal(t, "refresh1", store.tokens[refreshTokenKey].RefreshToken)
+
+	// the next run, once the access token expired, uses the persisted refresh token
+	expired := store.tokens["testClientID"]
+	expired.ExpiresAt = time.Now()
+	store.tokens["testClientID"] = expired
+	tokenResponse, err := newClient().fetchAccessToken(ctx, defaultAccountID)
+	require.NoError(t, err)
+	require.Equal(t, "token2", tokenResponse.AccessToken)
+	require.Equal(t, string(GrantRefreshToken), requests[1].GrantType)
+	require.Equal(t, "refresh1", requests[1].RefreshToken)
+	require.Equal(t, "refresh2", store.tokens[refreshTokenKey].RefreshToken)
+
+	// a token rejected by SFMC is evicted but the refresh token is kept
+	c := newClient()
+	c.cache.Set(accessTokenCacheKey(defaultAccountID), "token2", cache.DefaultExpiration)
+	c.cache.Set(restInstanceURLCacheKey(defaultAccountID), "instanceURL", cache.DefaultExpiration)
+	_, err = c.refreshAccessToken(ctx, defaultAccountID, "token2")
+	require.NoError(t, err)
+	require.Equal(t, "refresh2", requests[2].RefreshToken)
+
+	// the refresh token this process rotated wins over an older one left in the store
+	store.tokens[refreshTokenKey] = domain.AccessToken{RefreshToken: "stale", IssuedAt: time.Now().Add(-time.Hour)}
+	delete(store.tokens, "testClientID")
+	c.cache.Delete(accessTokenCacheKey(defaultAccountID))
+	_, err = c.fetchAccessToken(ctx, defaultAccountID)
+	require.NoError(t, err)
+	require.Equal(t, "refresh3", requests[3].RefreshToken)
+
+	// missing scopes fail the token, the rotated refresh token is persisted anyway
+	grantedScope = "documents_and_images_read"
+	store.tokens = map[string]domain.AccessToken{refreshTokenKey: {RefreshToken: "refresh4"}}
+	_, err = newClient().fetchAccessToken(ctx, defaultAccountID)
+	require.ErrorIs(t, err, ErrScopeNotGranted)
+	require.ErrorContains(t, err, "saved_content_read")
+	require.Equal(t, "refresh5", store.tokens[refreshTokenKey].RefreshToken)
+	require.Empty(t, store.tokens["testClientID"].AccessToken)
+}
+
+func TestSalesforceClient_fetchAccessTokenRefreshTokenGrantBusinessUnits(t *testing.T) {
+	var mu sync.Mutex
+	used := map[string]bool{}
+	var requests []TokenRequest
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request TokenRequest
+		json.NewDecoder(r.Body).Decode(&request)
+
+		mu.Lock()
+		defer mu.Unlock()
+		requests = append(requests, request)
+		// a refresh token is valid once, whatever the business unit
+		if used[request.RefreshToken] {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		used[request.RefreshToken] = true
+
+		json.NewEncoder(w).Encode(TokenResponse{
+			AccessToken:     "token" + strconv.Itoa(request.AccountID),
+			ExpiresIn:       1200,
+			RestInstanceURL: "instanceURL",
+			RefreshToken:    "refresh" + strconv.Itoa(len(requests)),
+		})
+	}))
+	defer mockServer.Close()
+
+	store := &fakeTokenStore{tokens: map[string]domain.AccessToken{}}
+	c := &client{
+		config: Config{
+			AuthURL:    mockServer.URL,
+			ClientID:   "testClientID",
+			AccountIDs: []int{100, 200},
+			OAuth:      OAuthConfig{GrantType: GrantRefreshToken, RefreshToken: "refresh0"},
+		},
+		httpClient: http.DefaultClient,
+		cache:      cache.New(5*time.Minute, 10*time.Minute),
+		tokenStore: store,
+	}
+
+	// the business units request their tokens at the same time, each with the refresh
+	// token the other one rotated
+	var wg sync.WaitGroup
+	for _, accountID := range c.config.AccountIDs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			tokenResponse, err := c.fetchAccessToken(context.Background(), accountID)
+			require.NoError(t, err)
+			require.Equal(t, "token"+strconv.Itoa(accountID), tokenResponse.AccessToken)
+		}()
+	}
+	wg.Wait()
+
+	require.Len(t, requests, 2)
+	require.Equal(t, "refresh0", requests[0].RefreshToken)
+	require.Equal(t, "refresh1", requests[1].RefreshToken)
+
+	// one refresh token is stored for the client, the access tokens by business unit
+	require.Equal(t, "refresh2", store.tokens["testClientID:refreshToken"].RefreshToken)
+	require.Equal(t, "token100", store.tokens["testClientID:100"].AccessToken)
+	require.Equal(t, "token200", store.tokens["testClientID:200"].AccessToken)
+	require.Empty(t, store.tokens["testClientID:100"].RefreshToken)
+}
+
+func TestOAuthConfig_validate(t *testing.T) {
+	require.NoError(t, OAuthConfig{}.validate())
+	require.NoError(t, OAuthConfig{GrantType: GrantRefreshToken, RefreshToken: "refresh"}.validate())
+	require.Error(t, OAuthConfig{GrantType: GrantRefreshToken, AuthorizationCode: "code"}.validate())
+	require.Error(t, OAuthConfig{GrantType: "password"}.validate())
+}
@@ -0,0 +1,161 @@
+package salesforce
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"jet-example/internal/domain"
+)
+
+// stableSortProperty is unique and never changes, so sorting on it last
+// gives every asset a fixed position between pages
+const stableSortProperty = "id"
+
+// withStableSort appends the asset ID to the sort order unless it is sorted on already
+func withStableSort(sort []domain.Sort) []domain.Sort {
+	for _, s := range sort {
+		if s.Property == stableSortProperty {
+			return sort
+		}
+	}
+	return append(sort[:len(sort):len(sort)], domain.Sort{Property: stableSortProperty, Direction: domain.SortAsc})
+}
+
+// pageConsistency tracks the pages of a business unit to drop the assets a page shift
+// handed over twice and to detect that assets were added or deleted during the fetch
+type pageConsistency struct {
+	count   int
+	seenIDs map[int]bool
+	drifted bool
+	// lastID is the highest ID of the pages checked before the drift
+	lastID int
+}
+
+func newPageConsistency(count int) *pageConsistency {
+	return &pageConsistency{
+		count:   count,
+		seenIDs: map[int]bool{},
+	}
+}
+
+// check records the count a page reported and returns its assets not seen before
+func (p *pageConsistency) check(count int, assets []domain.Asset) []domain.Asset {
+	if count != p.count {
+		p.drifted = true
+	}
+
+	unseen := assets[:0]
+	pageLastID := 0
+	for _, asset := range assets {
+		pageLastID = max(pageLastID, asset.ID)
+		// an asset without ID cannot be told apart from others
+		if asset.ID == 0 {
+			unseen = append(unseen, asset)
+			continue
+		}
+		if p.seenIDs[asset.ID] {
+			// a duplicate means assets moved between pages, so others may have been skipped
+			p.drifted = true
+			continue
+		}
+		p.seenIDs[asset.ID] = true
+		unseen = append(unseen, asset)
+	}
+	if !p.drifted {
+		p.lastID = max(p.lastID, pageLastID)
+	}
+	return unseen
+}
+
+// resumeAfter returns the ID the ID range pass starts after. Pages sorted by ID first were
+// complete up to the last ID checked before the drift, so only later IDs are fetched again.
+// Other sort orders give no such bound, the pass then goes through every ID.
+func (p *pageConsistency) resumeAfter(sort []domain.Sort) int {
+	if len(sort) == 0 || sort[0] != (domain.Sort{Property: stableSortProperty, Direction: domain.SortAsc}) {
+		return 0
+	}
+	return p.lastID
+}
+
+// pageOrder holds back the pages that arrive before the ones preceding them, so pages
+// fetched concurrently are handed over in page order and two runs over the same
+// assets produce the same output
+type pageOrder struct {
+	next    int
+	pending map[int]pageResult
+}
+
+func newPageOrder(firstPage int) *pageOrder {
+	return &pageOrder{
+		next:    firstPage,
+		pending: map[int]pageResult{},
+	}
+}
+
+// add records an arrived page and returns the pages now ready, in page order
+func (o *pageOrder) add(result pageResult) []pageResult {
+	o.pending[result.page] = result
+
+	var ready []pageResult
+	for {
+		next, ok := o.pending[o.next]
+		if !ok {
+			return ready
+		}
+		delete(o.pending, o.next)
+		ready = append(ready, next)
+		o.next++
+	}
+}
+
+// fetchByIDRange pages through the assets with an ID above afterID in ID order, each page
+// starting after the last ID of the previous one. Unlike page numbers, an ID range does not
+// shift when assets are added or deleted, so it recovers the assets skipped by a drifted fetch.
+// The recovered assets come sorted by ID whatever the sort order of the request.
+// Pages are numbered after firstPage and handed over like the others.
+func (c *client) fetchByIDRange(
+	ctx context.Context,
+	accountID int,
+	request domain.ContentBlocksRequest,
+	afterID int,
+	firstPage int,
+	handle func(result pageResult) bool,
+) {
+	query := request.Query
+	request.Sort = []domain.Sort{{Property: stableSortProperty, Direction: domain.SortAsc}}
+	request.Page.Page = 1
+
+	lastID := afterID
+	for page := firstPage; ; page++ {
+		request.Query = domain.And(query, domain.GreaterThan(stableSortProperty, lastID))
+		response, err := c.fetchAssetPage(ctx, accountID, request)
+		if err != nil {
+			handle(pageResult{page: page, err: fmt.Errorf("failed to fetch assets after ID %d: %w", lastID, err)})
+			return
+		}
+		if len(response.Items) == 0 {
+			return
+		}
+
+		// the last ID is taken before the page is filtered,
+		// a range that does not move forward would never end
+		pageLastID := response.Items[len(response.Items)-1].ID
+		if pageLastID <= lastID {
+			handle(pageResult{page: page, err: fmt.Errorf("assets after ID %d are not sorted by ID", lastID)})
+			return
+		}
+		lastID = pageLastID
+		if !handle(pageResult{page: page, contentBlocks: response.Items, count: response.Count}) {
+			return
+		}
+	}
+}
+
+func logDrift(accountID int) {
+	if accountID == defaultAccountID {
+		log.Println("assets changed during the fetch, fetching them again by ID range")
+		return
+	}
+	log.Printf("business unit %d: assets changed during the fetch, fetching them again by ID range", accountID)
+}
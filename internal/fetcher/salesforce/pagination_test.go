@@ -0,0 +1,201 @@
+package salesforce
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/patrickmn/go-cache"
+	"github.com/stretchr/testify/require"
+
+	"jet-example/internal/domain"
+)
+
+func TestWithStableSort(t *testing.T) {
+	byDate := domain.Sort{Property: "modifiedDate", Direction: domain.SortDesc}
+	byID := domain.Sort{Property: "id", Direction: domain.SortAsc}
+
+	require.Equal(t, []domain.Sort{byID}, withStableSort(nil))
+	require.Equal(t, []domain.Sort{byDate, byID}, withStableSort([]domain.Sort{byDate}))
+	require.Equal(t, []domain.Sort{byID, byDate}, withStableSort([]domain.Sort{byID, byDate}))
+}
+
+func TestSalesforceClient_FetchContentBlocksPageDrift(t *testing.T) {
+	deleteSecond := func(ids []int) []int { return slices.DeleteFunc(ids, func(id int) bool { return id == 2 }) }
+	tests := []struct {
+		name    string
+		sort    []domain.Sort
+		change  func(ids []int) []int
+		wantIDs []int
+		// wantRangeAfter is the ID the ID range pass starts after, -1 when there is none
+		wantRangeAfter int
+	}{
+		{
+			name:           "Stable catalog",
+			change:         func(ids []int) []int { return ids },
+			wantIDs:        []int{1, 2, 3, 4, 5},
+			wantRangeAfter: -1,
+		},
+		{
+			name: "Asset deleted after the first page",
+			// the next assets move one position up, so asset 3 would be skipped
+			change:         deleteSecond,
+			wantIDs:        []int{1, 2, 3, 4, 5},
+			wantRangeAfter: 2,
+		},
+		{
+			name:           "Asset added after the first page",
+			change:         func(ids []int) []int { return append(ids, 6) },
+			wantIDs:        []int{1, 2, 3, 4, 5, 6},
+			wantRangeAfter: 2,
+		},
+		{
+			name: "Asset deleted from pages sorted by date",
+			// the pages handed over give no bound on the IDs of the skipped assets
+			sort:           []domain.Sort{{Property: "modifiedDate", Direction: domain.SortDesc}},
+			change:         deleteSecond,
+			wantIDs:        []int{1, 2, 3, 4, 5},
+			wantRangeAfter: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var mu sync.Mutex
+			ids := []int{1, 2, 3, 4, 5}
+			changed := false
+			rangeAfter := -1
+
+			mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var request domain.ContentBlocksRequest
+				json.NewDecoder(r.Body).Decode(&request)
+				require.Equal(t, stableSortProperty, request.Sort[len(request.Sort)-1].Property)
+
+				mu.Lock()
+				defer mu.Unlock()
+
+				pageSize := request.Page.PageSize
+				var items []domain.Asset
+				if request.Query != nil && request.Query.Property == stableSortProperty {
+					// ID range page
+					after := int(request.Query.Value.(float64))
+					if rangeAfter < 0 {
+						rangeAfter = after
+					}
+					for _, id := range ids {
+						if id > after && len(items) < pageSize {
+							items = append(items, domain.Asset{ID: id})
+						}
+					}
+				} else {
+					from := (request.Page.Page - 1) * pageSize
+					for _, id := range ids[min(from, len(ids)):min(from+pageSize, len(ids))] {
+						items = append(items, domain.Asset{ID: id})
+					}
+				}
+				json.NewEncoder(w).Encode(ContentAssetsResponse{
+					Count:    len(ids),
+					Page:     request.Page.Page,
+					PageSize: pageSize,
+					Items:    items,
+				})
+
+				// the catalog changes once the first page is served
+				if !changed {
+					changed = true
+					ids = tt.change(ids)
+				}
+			}))
+			defer mockServer.Close()
+
+			c := &client{
+				config: Config{
+					AuthURL:            mockServer.URL,
+					MaxConcurrentPages: 1,
+				},
+				httpClient: http.DefaultClient,
+				cache:      cache.New(5*time.Minute, 10*time.Minute),
+			}
+			c.cache.Set(cacheKeyAccessTokenKey, "testAccessToken", cache.DefaultExpiration)
+			c.cache.Set(cacheKeyRestInstanceURLKey, mockServer.URL, cache.DefaultExpiration)
+
+			var gotIDs []int
+			totalPages, err := c.fetchBusinessUnitPages(context.Background(), defaultAccountID, domain.ContentBlocksRequest{
+				Page: domain.Page{PageSize: 2},
+				Sort: tt.sort,
+			}, func(result pageResult) bool {
+				require.NoError(t, result.err)
+				for _, asset := range result.contentBlocks {
+					gotIDs = append(gotIDs, asset.ID)
+				}
+				return true
+			})
+
+			require.NoError(t, err)
+			// every asset is handed over exactly once
+			slices.Sort(gotIDs)
+			require.Equal(t, tt.wantIDs, gotIDs)
+			require.Equal(t, tt.wantRangeAfter, rangeAfter)
+			// the pages of the ID range pass are not counted
+			require.Equal(t, 3, totalPages)
+		})
+	}
+}
+
+func TestSalesforceClient_FetchContentBlocksPageOrder(t *testing.T) {
+	const totalPages = 5
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request domain.ContentBlocksRequest
+		json.NewDecoder(r.Body).Decode(&request)
+		require.Equal(t, []domain.Sort{
+			{Property: "modifiedDate", Direction: domain.SortDesc},
+			{Property: stableSortProperty, Direction: domain.SortAsc},
+		}, request.Sort)
+
+		// later pages answer first
+		time.Sleep(time.Duration(totalPages-request.Page.Page) * 10 * time.Millisecond)
+		json.NewEncoder(w).Encode(ContentAssetsResponse{
+			Count:    totalPages,
+			Page:     request.Page.Page,
+			PageSize: 1,
+			Items:    []domain.Asset{{ID: request.Page.Page}},
+		})
+	}))
+	defer mockServer.Close()
+
+	c := &client{
+		config: Config{
+			AuthURL:            mockServer.URL,
+			SortProperty:       "modifiedDate",
+			SortDirection:      domain.SortDesc,
+			MaxConcurrentPages: totalPages,
+		},
+		httpClient: http.DefaultClient,
+		cache:      cache.New(5*time.Minute, 10*time.Minute),
+	}
+	c.cache.Set(cacheKeyAccessTokenKey, "testAccessToken", cache.DefaultExpiration)
+	c.cache.Set(cacheKeyRestInstanceURLKey, mockServer.URL, cache.DefaultExpiration)
+
+	wantIDs := []int{1, 2, 3, 4, 5}
+
+	got, err := c.FetchContentBlocks(context.Background(), domain.ContentBlocksRequest{})
+	require.NoError(t, err)
+	var gotIDs []int
+	for _, asset := range got {
+		gotIDs = append(gotIDs, asset.ID)
+	}
+	require.Equal(t, wantIDs, gotIDs)
+
+	// the stream yields the same order
+	gotIDs = nil
+	for asset, err := range c.StreamContentBlocks(context.Background(), domain.ContentBlocksRequest{}) {
+		require.NoError(t, err)
+		gotIDs = append(gotIDs, asset.ID)
+	}
+	require.Equal(t, wantIDs, gotIDs)
+}
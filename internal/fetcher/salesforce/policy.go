@@ -0,0 +1,109 @@
+package salesforce
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"jet-example/internal/domain"
+)
+
+// PartialFailurePolicy decides what happens when some pages of assets cannot be fetched
+type PartialFailurePolicy string
+
+const (
+	// PartialFailureFailFast aborts the fetch on the first failed page
+	PartialFailureFailFast PartialFailurePolicy = "fail-fast"
+	// PartialFailureBestEffort returns whatever pages were fetched
+	PartialFailureBestEffort PartialFailurePolicy = "best-effort"
+	// PartialFailureThreshold returns fetched pages unless more than
+	// Config.MaxFailedPagesPercent of all pages failed
+	PartialFailureThreshold PartialFailurePolicy = "threshold"
+)
+
+// validatePartialFailure rejects unknown policies and percentages, an empty policy fails fast
+func (c Config) validatePartialFailure() error {
+	switch c.PartialFailurePolicy {
+	case "", PartialFailureFailFast, PartialFailureBestEffort, PartialFailureThreshold:
+	default:
+		return fmt.Errorf("unknown partial failure policy %q", c.PartialFailurePolicy)
+	}
+	if !(c.MaxFailedPagesPercent >= 0 && c.MaxFailedPagesPercent <= 100) {
+		return fmt.Errorf("max failed pages percent %v is not between 0 and 100", c.MaxFailedPagesPercent)
+	}
+	return nil
+}
+
+// failsFast reports whether the first failed page should abort the fetch
+func (c Config) failsFast() bool {
+	return c.PartialFailurePolicy != PartialFailureBestEffort &&
+		c.PartialFailurePolicy != PartialFailureThreshold
+}
+
+// evaluatePartialResult applies the partial failure policy once all pages are done.
+// It returns nil when no page failed.
+func (c Config) evaluatePartialResult(
+	contentBlocks []domain.Asset,
+	totalPages int,
+	failedPages []domain.PageError,
+) ([]domain.Asset, error) {
+	err := c.partialResultError(totalPages, failedPages)
+	if err != nil && !domain.IsPartialResult(err) {
+		return nil, err
+	}
+	return contentBlocks, err
+}
+
+// partialResultError reports the failed pages as a PartialResultError,
+// marked accepted or wrapped as rejected according to the policy
+func (c Config) partialResultError(totalPages int, failedPages []domain.PageError) error {
+	if len(failedPages) == 0 {
+		return nil
+	}
+
+	slices.SortFunc(failedPages, func(a, b domain.PageError) int {
+		if a.BusinessUnitID != b.BusinessUnitID {
+			return a.BusinessUnitID - b.BusinessUnitID
+		}
+		return a.Page - b.Page
+	})
+	partialErr := &domain.PartialResultError{
+		TotalPages:  totalPages,
+		FailedPages: failedPages,
+	}
+
+	switch c.PartialFailurePolicy {
+	case PartialFailureBestEffort:
+		partialErr.Accepted = true
+		return partialErr
+	case PartialFailureThreshold:
+		failedPercent := float64(len(failedPages)) / float64(totalPages) * 100
+		if failedPercent <= c.MaxFailedPagesPercent {
+			partialErr.Accepted = true
+			return partialErr
+		}
+		return fmt.Errorf(
+			"failed pages exceed %.2f%% threshold: %w",
+			c.MaxFailedPagesPercent,
+			partialErr,
+		)
+	default:
+		return fmt.Errorf("failed to fetch all pages: %w", partialErr)
+	}
+}
+
+// withoutCancelledPages drops the pages that only failed because the fetch was cancelled,
+// unless cancellation is all there is to report
+func withoutCancelledPages(failedPages []domain.PageError) []domain.PageError {
+	var causes []domain.PageError
+	for _, pageErr := range failedPages {
+		if !errors.Is(pageErr.Err, context.Canceled) {
+			causes = append(causes, pageErr)
+		}
+	}
+	if len(causes) == 0 {
+		return failedPages
+	}
+	return causes
+}
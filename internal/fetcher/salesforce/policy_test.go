@@ -0,0 +1,18 @@
+package salesforce
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfig_validatePartialFailure(t *testing.T) {
+	require.NoError(t, Config{}.validatePartialFailure())
+	require.NoError(t, Config{PartialFailurePolicy: PartialFailureBestEffort}.validatePartialFailure())
+	require.NoError(t, Config{PartialFailurePolicy: PartialFailureThreshold, MaxFailedPagesPercent: 100}.validatePartialFailure())
+	require.Error(t, Config{PartialFailurePolicy: "best_effort"}.validatePartialFailure())
+	require.Error(t, Config{PartialFailurePolicy: PartialFailureThreshold, MaxFailedPagesPercent: -1}.validatePartialFailure())
+	require.Error(t, Config{PartialFailurePolicy: PartialFailureThreshold, MaxFailedPagesPercent: 150}.validatePartialFailure())
+	require.Error(t, Config{PartialFailurePolicy: PartialFailureThreshold, MaxFailedPagesPercent: math.NaN()}.validatePartialFailure())
+}
@@ -0,0 +1,488 @@
+package salesforce
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/patrickmn/go-cache"
+
+	"jet-example/internal/domain"
+)
+
+type PublishConfig struct {
+	// AccountID is the MID of the business unit the assets are published to
+	AccountID int `env:"SALESFORCE_PUBLISH_ACCOUNT_ID"`
+	// AuthURL, ClientID and ClientSecret of the target tenant, the source ones when empty.
+	// Another client always uses the client_credentials grant with the scopes of its package.
+	AuthURL      string `env:"SALESFORCE_PUBLISH_AUTH_URL"`
+	ClientID     string `env:"SALESFORCE_PUBLISH_CLIENT_ID"`
+	ClientSecret string `env:"SALESFORCE_PUBLISH_CLIENT_SECRET"`
+	// DryRun only logs the plan of creates, updates and skips, the target is not changed
+	DryRun bool `env:"SALESFORCE_PUBLISH_DRY_RUN" envDefault:"true"`
+}
+
+// foreignClient reports whether the target is reached with a client other than the source one
+func (c PublishConfig) foreignClient(source Config) bool {
+	return c.ClientID != "" && c.ClientID != source.ClientID
+}
+
+// validatePublish rejects a target client that would be sent the OAuth settings of the
+// source one, the refresh token and authorization code belong to the source client
+func (c Config) validatePublish() error {
+	if c.Publish.foreignClient(c) && c.OAuth.GrantType == GrantRefreshToken {
+		return errors.New("a publish client ID needs the client_credentials grant, the refresh token belongs to the source client")
+	}
+	return nil
+}
+
+// publishLookupBatchSize is the number of customer keys looked up in the target per query
+const publishLookupBatchSize = 50
+
+// publishedProperties are the asset properties copied to the target business unit,
+// IDs, dates, owners and status belong to the source and are left out
+var publishedProperties = []string{
+	"customerKey", "name", "description", "assetType", "content", "superContent",
+	"design", "meta", "data", "views", "slots", "blocks", "template",
+}
+
+// PublishOperation is what publishing does with an asset
+type PublishOperation string
+
+const (
+	PublishCreate PublishOperation = "create"
+	PublishUpdate PublishOperation = "update"
+	PublishSkip   PublishOperation = "skip"
+)
+
+// PublishStep is the operation planned for a single asset
+type PublishStep struct {
+	Operation    PublishOperation
+	CustomerKey  string
+	Name         string
+	CategoryPath string
+	// TargetID is the ID of the asset with the same customer key in the target, zero when created
+	TargetID int
+	// Reason tells why an asset is skipped
+	Reason string
+
+	payload map[string]json.RawMessage
+}
+
+// PublishPlan lists what publishing changes in the target business unit
+type PublishPlan struct {
+	// Folders are the category paths created in the target, parents first
+	Folders []string
+	Steps   []PublishStep
+}
+
+// count returns the number of steps of an operation
+func (p PublishPlan) count(operation PublishOperation) int {
+	count := 0
+	for _, step := range p.Steps {
+		if step.Operation == operation {
+			count++
+		}
+	}
+	return count
+}
+
+// String lists the folders and steps of the plan, one per line
+func (p PublishPlan) String() string {
+	var builder strings.Builder
+	fmt.Fprintf(
+		&builder,
+		"%d folders to create, %d assets to create, %d to update, %d to skip",
+		len(p.Folders), p.count(PublishCreate), p.count(PublishUpdate), p.count(PublishSkip),
+	)
+	for _, folder := range p.Folders {
+		fmt.Fprintf(&builder, "\n  create folder %q", folder)
+	}
+	for _, step := range p.Steps {
+		fmt.Fprintf(&builder, "\n  %s %q (%s)", step.Operation, step.CustomerKey, step.Name)
+		if step.TargetID != 0 {
+			fmt.Fprintf(&builder, " asset %d", step.TargetID)
+		}
+		if step.CategoryPath != "" {
+			fmt.Fprintf(&builder, " in %q", step.CategoryPath)
+		}
+		if step.Reason != "" {
+			fmt.Fprintf(&builder, ": %s", step.Reason)
+		}
+	}
+	return builder.String()
+}
+
+// publisher creates or updates assets in a target business unit with the auth,
+// token cache, retries and rate limit of the Salesforce client
+type publisher struct {
+	client    *client
+	accountID int
+	dryRun    bool
+}
+
+// NewPublisher publishes content blocks to the business unit of config.Publish, e.g. to
+// promote them from a staging to a production business unit. Assets are matched by
+// customer key and keep their category path, missing folders are created. Category paths
+// must be resolved by the fetcher. References to other assets by ID are not remapped.
+// The cache should not be shared with a client of another tenant, tokens are cached by MID.
+func NewPublisher(
+	config Config,
+	httpClient *http.Client,
+	cache *cache.Cache,
+	tokenStore domain.TokenStore,
+) domain.Uploader {
+	target := config.Publish
+	if target.AuthURL != "" {
+		config.AuthURL = target.AuthURL
+	}
+	if target.foreignClient(config) {
+		config.ClientID = target.ClientID
+		config.ClientSecret = target.ClientSecret
+		// the grant, tokens and scopes of the source client do not apply to another one
+		config.OAuth = OAuthConfig{GrantType: GrantClientCredentials}
+	}
+	config.AccountIDs = []int{target.AccountID}
+
+	return &publisher{
+		client: &client{
+			config:         config,
+			httpClient:     httpClient,
+			cache:          cache,
+			tokenStore:     tokenStore,
+			session:        &session{limiter: newRateLimiter(config.RateLimit)},
+			budget:         newCallBudget(config.RateLimit.CallBudget),
+			requiredScopes: []string{"saved_content_read", "saved_content_write"},
+		},
+		accountID: target.AccountID,
+		dryRun:    target.DryRun,
+	}
+}
+
+// StartRun resets the API call budget, every call until the next run counts against it
+func (p *publisher) StartRun() {
+	p.client.StartRun()
+}
+
+// UploadContentBlocks plans the changes to the target business unit and applies them
+// unless running dry. A failed asset does not stop the others.
+func (p *publisher) UploadContentBlocks(ctx context.Context, contentBlocks []domain.Asset) error {
+	categories, err := p.client.fetchCategories(ctx, p.accountID)
+	if err != nil {
+		return p.client.businessUnitError(p.accountID, err)
+	}
+	folders := newFolderIndex(categories)
+
+	plan, err := p.plan(ctx, contentBlocks, folders)
+	if err != nil {
+		return p.client.businessUnitError(p.accountID, err)
+	}
+
+	if p.dryRun {
+		log.Printf("publish plan (dry run): %s", plan)
+		return nil
+	}
+	log.Printf("publish plan: %s", plan)
+
+	if err := p.apply(ctx, plan, folders); err != nil {
+		return p.client.businessUnitError(p.accountID, err)
+	}
+	return nil
+}
+
+// plan matches the assets with the ones of the target by customer key
+func (p *publisher) plan(
+	ctx context.Context,
+	contentBlocks []domain.Asset,
+	folders *folderIndex,
+) (PublishPlan, error) {
+	var keys []string
+	for _, asset := range contentBlocks {
+		if asset.CustomerKey != "" {
+			keys = append(keys, asset.CustomerKey)
+		}
+	}
+	targets, err := p.fetchTargetAssets(ctx, keys)
+	if err != nil {
+		return PublishPlan{}, err
+	}
+
+	var plan PublishPlan
+	missingFolders := map[string]bool{}
+	for _, asset := range contentBlocks {
+		step := PublishStep{
+			CustomerKey:  asset.CustomerKey,
+			Name:         asset.Name,
+			CategoryPath: asset.Category.Path,
+		}
+
+		switch {
+		case asset.CustomerKey == "":
+			step.Operation, step.Reason = PublishSkip, "no customer key to match it by"
+		case asset.FileProperties != nil:
+			step.Operation, step.Reason = PublishSkip, "binary assets are not published"
+		case asset.AssetType.Name == DataExtensionAssetType:
+			step.Operation, step.Reason = PublishSkip, "Data Extensions are not published"
+		case asset.Category.Path == "":
+			step.Operation, step.Reason = PublishSkip, "unknown category path"
+		default:
+			step.payload, err = publishPayload(asset)
+			if err != nil {
+				return PublishPlan{}, fmt.Errorf("failed to prepare asset %q: %w", asset.CustomerKey, err)
+			}
+
+			target, found := targets[asset.CustomerKey]
+			switch {
+			case !found:
+				step.Operation = PublishCreate
+			case target.Category.ID == folders.ids[asset.Category.Path] && samePayload(step.payload, target):
+				step.Operation, step.TargetID, step.Reason = PublishSkip, target.ID, "unchanged"
+				step.payload = nil
+			default:
+				step.Operation, step.TargetID = PublishUpdate, target.ID
+			}
+
+			for _, folder := range folders.missing(asset.Category.Path) {
+				if !missingFolders[folder] {
+					missingFolders[folder] = true
+					plan.Folders = append(plan.Folders, folder)
+				}
+			}
+		}
+
+		plan.Steps = append(plan.Steps, step)
+	}
+
+	return plan, nil
+}
+
+// fetchTargetAssets looks the customer keys up in the target business unit
+func (p *publisher) fetchTargetAssets(ctx context.Context, keys []string) (map[string]domain.Asset, error) {
+	targets := map[string]domain.Asset{}
+	for start := 0; start < len(keys); start += publishLookupBatchSize {
+		batch := keys[start:min(start+publishLookupBatchSize, len(keys))]
+		request := domain.ContentBlocksRequest{
+			Page:  domain.Page{Page: 1, PageSize: len(batch)},
+			Query: domain.In("customerKey", batch...),
+		}
+
+		response, err := p.client.fetchAssetPage(ctx, p.accountID, request)
+		if err != nil {
+			return nil, fmt.Errorf("failed to look up assets in the target: %w", err)
+		}
+		for _, asset := range response.Items {
+			targets[asset.CustomerKey] = asset
+		}
+	}
+
+	return targets, nil
+}
+
+// apply creates the missing folders, then creates and updates the assets
+func (p *publisher) apply(ctx context.Context, plan PublishPlan, folders *folderIndex) error {
+	for _, folder := range plan.Folders {
+		if err := p.createFolder(ctx, folder, folders); err != nil {
+			return err
+		}
+	}
+
+	var errs []error
+	for _, step := range plan.Steps {
+		if step.Operation == PublishSkip {
+			continue
+		}
+		if err := p.publishAsset(ctx, step, folders.ids[step.CategoryPath]); err != nil {
+			errs = append(errs, fmt.Errorf("failed to %s asset %q: %w", step.Operation, step.CustomerKey, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// createFolder creates a folder under its parent, which exists or was created before
+func (p *publisher) createFolder(ctx context.Context, folder string, folders *folderIndex) error {
+	parent, name := splitCategoryPath(folder)
+	parentID, found := folders.ids[parent]
+	if !found {
+		return fmt.Errorf("failed to create folder %q: no parent folder %q in the target", folder, parent)
+	}
+
+	// a create that failed may have been processed, retrying it would create the folder twice
+	body := map[string]any{"name": name, "parentId": parentID, "categoryType": "asset"}
+	var category domain.Category
+	err := p.client.callOnceWithToken(ctx, p.accountID, func(tokenResponse TokenResponse) error {
+		return p.send(ctx, tokenResponse, http.MethodPost, "/asset/v1/content/categories", body, &category)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create folder %q: %w", folder, err)
+	}
+
+	folders.ids[folder] = category.ID
+	return nil
+}
+
+// publishAsset creates or updates a single asset in the given category
+func (p *publisher) publishAsset(ctx context.Context, step PublishStep, categoryID int) error {
+	categoryJSON, err := json.Marshal(domain.Category{ID: categoryID})
+	if err != nil {
+		return err
+	}
+	body := make(map[string]json.RawMessage, len(step.payload)+1)
+	for property, value := range step.payload {
+		body[property] = value
+	}
+	body["category"] = categoryJSON
+
+	method, assetPath := http.MethodPost, "/asset/v1/content/assets"
+	if step.Operation == PublishUpdate {
+		method, assetPath = http.MethodPut, assetPath+"/"+strconv.Itoa(step.TargetID)
+	}
+
+	// updates are retried, a create that failed may have been processed and
+	// retrying it would fail on its customer key, the next publish updates it instead
+	call := p.client.callWithToken
+	if step.Operation == PublishCreate {
+		call = p.client.callOnceWithToken
+	}
+	return call(ctx, p.accountID, func(tokenResponse TokenResponse) error {
+		return p.send(ctx, tokenResponse, method, assetPath, body, nil)
+	})
+}
+
+// send calls the REST API with a JSON body and decodes the response into out unless nil
+func (p *publisher) send(
+	ctx context.Context,
+	tokenResponse TokenResponse,
+	method string,
+	path string,
+	body any,
+	out any,
+) error {
+	requestBody, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("failed to marshal request body: %w", err)
+	}
+
+	httpRequest, err := http.NewRequestWithContext(
+		ctx,
+		method,
+		tokenResponse.RestInstanceURL+path,
+		bytes.NewReader(requestBody),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create HTTP request: %w", err)
+	}
+	httpRequest.Header.Set("Authorization", "Bearer "+tokenResponse.AccessToken)
+	httpRequest.Header.Set("Content-Type", "application/json")
+
+	httpResponse, err := p.client.httpClient.Do(httpRequest)
+	if err != nil {
+		return fmt.Errorf("failed to perform HTTP request: %w", err)
+	}
+	defer httpResponse.Body.Close()
+
+	if httpResponse.StatusCode != http.StatusOK && httpResponse.StatusCode != http.StatusCreated {
+		return newStatusError(method+" "+path, httpResponse)
+	}
+
+	if out == nil {
+		_, err = io.Copy(io.Discard, httpResponse.Body)
+		return err
+	}
+	if err := json.NewDecoder(httpResponse.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode response body: %w", err)
+	}
+
+	return nil
+}
+
+// publishPayload keeps the published properties of the asset JSON, taken from the raw
+// asset so the properties of views and slots not modelled by domain.Asset are published too
+func publishPayload(asset domain.Asset) (map[string]json.RawMessage, error) {
+	assetJSON := []byte(asset.Raw)
+	if len(assetJSON) == 0 {
+		var err error
+		if assetJSON, err = json.Marshal(asset); err != nil {
+			return nil, err
+		}
+	}
+	var properties map[string]json.RawMessage
+	if err := json.Unmarshal(assetJSON, &properties); err != nil {
+		return nil, err
+	}
+
+	payload := make(map[string]json.RawMessage, len(publishedProperties))
+	for _, property := range publishedProperties {
+		if value, found := properties[property]; found {
+			payload[property] = value
+		}
+	}
+	return payload, nil
+}
+
+// samePayload reports whether the target asset already holds the published properties,
+// comparing decoded values so the order of properties and whitespace do not matter
+func samePayload(payload map[string]json.RawMessage, target domain.Asset) bool {
+	targetPayload, err := publishPayload(target)
+	if err != nil || len(targetPayload) != len(payload) {
+		return false
+	}
+
+	for property, value := range payload {
+		var want, got any
+		if json.Unmarshal(value, &want) != nil || json.Unmarshal(targetPayload[property], &got) != nil {
+			return false
+		}
+		if !reflect.DeepEqual(want, got) {
+			return false
+		}
+	}
+	return true
+}
+
+// folderIndex maps the category paths of the target business unit to their IDs
+type folderIndex struct {
+	ids map[string]int
+}
+
+func newFolderIndex(categories []domain.Category) *folderIndex {
+	tree := newCategoryTree(categories)
+	index := &folderIndex{ids: make(map[string]int, len(categories))}
+	for _, category := range categories {
+		index.ids[tree.path(category.ID)] = category.ID
+	}
+	return index
+}
+
+// missing returns the folders of a category path not in the target, parents first
+func (i *folderIndex) missing(categoryPath string) []string {
+	var missing []string
+	for folder := categoryPath; folder != ""; folder, _ = splitCategoryPath(folder) {
+		if _, found := i.ids[folder]; found {
+			break
+		}
+		missing = append(missing, folder)
+	}
+
+	// collected from the deepest folder up
+	slices.Reverse(missing)
+	return missing
+}
+
+// splitCategoryPath splits a category path into the path of its parent and its name
+func splitCategoryPath(categoryPath string) (parent, name string) {
+	index := strings.LastIndex(categoryPath, "/")
+	if index < 0 {
+		return "", categoryPath
+	}
+	return categoryPath[:index], categoryPath[index+1:]
+}
@@ -0,0 +1,257 @@
+package salesforce
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/patrickmn/go-cache"
+	"github.com/stretchr/testify/require"
+
+	"jet-example/internal/domain"
+)
+
+func TestPublisher_UploadContentBlocks(t *testing.T) {
+	const targetAccountID = 300
+	htmlBlock := domain.AssetType{ID: 197, Name: "htmlblock"}
+	footer := domain.Asset{
+		ID: 11, CustomerKey: "footer", Name: "Footer", AssetType: htmlBlock, Content: "<p>footer</p>",
+		Category: domain.Category{ID: 5, Path: "Content Builder/Footers"},
+	}
+	header := domain.Asset{
+		ID: 12, CustomerKey: "header", Name: "Header", AssetType: htmlBlock, Content: "<p>new header</p>",
+		Category: domain.Category{ID: 5, Path: "Content Builder/Footers"},
+	}
+	promo := domain.Asset{
+		ID: 13, CustomerKey: "promo", Name: "Promo", AssetType: htmlBlock, Content: "<p>promo</p>",
+		Category: domain.Category{ID: 6, Path: "Content Builder/Campaigns/Spring"},
+	}
+	unkeyed := domain.Asset{ID: 14, Name: "Unkeyed", AssetType: htmlBlock, Category: footer.Category}
+
+	type write struct {
+		method string
+		path   string
+		body   map[string]any
+	}
+
+	tests := []struct {
+		name       string
+		dryRun     bool
+		wantWrites []write
+	}{
+		{
+			name:   "Dry run only plans",
+			dryRun: true,
+		},
+		{
+			name: "Apply creates folders, creates and updates assets",
+			wantWrites: []write{
+				{http.MethodPost, "/asset/v1/content/categories", map[string]any{
+					"name": "Campaigns", "parentId": float64(1), "categoryType": "asset",
+				}},
+				{http.MethodPost, "/asset/v1/content/categories", map[string]any{
+					"name": "Spring", "parentId": float64(100), "categoryType": "asset",
+				}},
+				{http.MethodPut, "/asset/v1/content/assets/22", map[string]any{
+					"customerKey": "header", "name": "Header", "content": "<p>new header</p>",
+					"assetType": map[string]any{"id": float64(197), "name": "htmlblock"},
+					"category":  map[string]any{"id": float64(2)},
+				}},
+				{http.MethodPost, "/asset/v1/content/assets", map[string]any{
+					"customerKey": "promo", "name": "Promo", "content": "<p>promo</p>",
+					"assetType": map[string]any{"id": float64(197), "name": "htmlblock"},
+					"category":  map[string]any{"id": float64(101)},
+				}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var mu sync.Mutex
+			var writes []write
+			nextCategoryID := 100
+
+			mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				mu.Lock()
+				defer mu.Unlock()
+
+				switch {
+				case r.Method == http.MethodGet && r.URL.Path == "/asset/v1/content/categories":
+					json.NewEncoder(w).Encode(CategoriesResponse{
+						Count:    2,
+						Page:     1,
+						PageSize: categoriesPageSize,
+						Items: []domain.Category{
+							{ID: 1, Name: "Content Builder"},
+							{ID: 2, Name: "Footers", ParentID: 1},
+						},
+					})
+				case r.URL.Path == "/asset/v1/content/assets/query":
+					var request domain.ContentBlocksRequest
+					json.NewDecoder(r.Body).Decode(&request)
+					require.Equal(t, domain.OperatorIn, request.Query.SimpleOperator)
+					require.ElementsMatch(t, []any{"footer", "header", "promo"}, request.Query.Value)
+
+					// footer is up to date, header differs
+					targetFooter, targetHeader := footer, header
+					targetFooter.ID, targetFooter.Category = 21, domain.Category{ID: 2}
+					targetHeader.ID, targetHeader.Category = 22, domain.Category{ID: 2}
+					targetHeader.Content = "<p>old header</p>"
+					json.NewEncoder(w).Encode(ContentAssetsResponse{
+						Count:    2,
+						Page:     1,
+						PageSize: 3,
+						Items:    []domain.Asset{targetFooter, targetHeader},
+					})
+				default:
+					var body map[string]any
+					json.NewDecoder(r.Body).Decode(&body)
+					writes = append(writes, write{r.Method, r.URL.Path, body})
+					if r.URL.Path == "/asset/v1/content/categories" {
+						json.NewEncoder(w).Encode(domain.Category{ID: nextCategoryID})
+						nextCategoryID++
+						return
+					}
+					w.WriteHeader(http.StatusCreated)
+				}
+			}))
+			defer mockServer.Close()
+
+			uploader := NewPublisher(
+				Config{Publish: PublishConfig{AccountID: targetAccountID, DryRun: tt.dryRun}},
+				http.DefaultClient,
+				cache.New(5*time.Minute, 10*time.Minute),
+				nil,
+			)
+			p := uploader.(*publisher)
+			p.client.cache.Set(p.client.tokenKey(accessTokenCacheKey(targetAccountID)), "testAccessToken", cache.DefaultExpiration)
+			p.client.cache.Set(p.client.tokenKey(restInstanceURLCacheKey(targetAccountID)), mockServer.URL, cache.DefaultExpiration)
+
+			err := uploader.UploadContentBlocks(context.Background(), []domain.Asset{footer, header, promo, unkeyed})
+			require.NoError(t, err)
+			require.Equal(t, tt.wantWrites, writes)
+		})
+	}
+}
+
+func TestPublisher_UploadContentBlocksFailedWrites(t *testing.T) {
+	const targetAccountID = 300
+	// the views hold properties domain.Asset does not model, they are published too
+	var email domain.Asset
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"id": 11, "customerKey": "email", "name": "Email", "assetType": {"id": 207, "name": "templatebasedemail"},
+		"views": {"html": {"content": "<p>email</p>", "meta": {"options": {"generateFrom": "html"}}, "template": {"id": 5}}}
+	}`), &email))
+	email.Category.Path = "Content Builder"
+	var header domain.Asset
+	require.NoError(t, json.Unmarshal([]byte(`{"id": 12, "customerKey": "header", "name": "Header", "content": "new"}`), &header))
+	header.Category.Path = "Content Builder"
+
+	var mu sync.Mutex
+	writes := map[string]int{}
+	var createdViews any
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		switch {
+		case r.Method == http.MethodGet:
+			json.NewEncoder(w).Encode(CategoriesResponse{
+				Count: 1, Page: 1, PageSize: categoriesPageSize,
+				Items: []domain.Category{{ID: 1, Name: "Content Builder"}},
+			})
+		case r.URL.Path == "/asset/v1/content/assets/query":
+			json.NewEncoder(w).Encode(ContentAssetsResponse{
+				Count: 1, Page: 1, PageSize: 2,
+				Items: []domain.Asset{{ID: 22, CustomerKey: "header", Content: "old"}},
+			})
+		default:
+			// every write fails the first time
+			writes[r.Method]++
+			if r.Method == http.MethodPost {
+				var body map[string]any
+				json.NewDecoder(r.Body).Decode(&body)
+				createdViews = body["views"]
+			}
+			if writes[r.Method] == 1 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		}
+	}))
+	defer mockServer.Close()
+
+	uploader := NewPublisher(
+		Config{
+			Publish: PublishConfig{AccountID: targetAccountID},
+			Retry:   RetryConfig{MaxAttempts: 3, BaseDelay: time.Millisecond},
+		},
+		http.DefaultClient,
+		cache.New(5*time.Minute, 10*time.Minute),
+		nil,
+	)
+	p := uploader.(*publisher)
+	p.client.cache.Set(p.client.tokenKey(accessTokenCacheKey(targetAccountID)), "testAccessToken", cache.DefaultExpiration)
+	p.client.cache.Set(p.client.tokenKey(restInstanceURLCacheKey(targetAccountID)), mockServer.URL, cache.DefaultExpiration)
+
+	// the create may have been processed, it is not sent again, the update is retried
+	err := uploader.UploadContentBlocks(context.Background(), []domain.Asset{email, header})
+	require.ErrorContains(t, err, `failed to create asset "email"`)
+	require.NotContains(t, err.Error(), "header")
+	require.Equal(t, map[string]int{http.MethodPost: 1, http.MethodPut: 2}, writes)
+	require.Equal(t, map[string]any{"html": map[string]any{
+		"content":  "<p>email</p>",
+		"meta":     map[string]any{"options": map[string]any{"generateFrom": "html"}},
+		"template": map[string]any{"id": float64(5)},
+	}}, createdViews)
+}
+
+func TestFolderIndex_missing(t *testing.T) {
+	folders := newFolderIndex([]domain.Category{
+		{ID: 1, Name: "Content Builder"},
+		{ID: 2, Name: "Campaigns", ParentID: 1},
+	})
+
+	require.Empty(t, folders.missing("Content Builder/Campaigns"))
+	require.Equal(t,
+		[]string{"Content Builder/Campaigns/Spring", "Content Builder/Campaigns/Spring/Week 1"},
+		folders.missing("Content Builder/Campaigns/Spring/Week 1"),
+	)
+}
+
+func TestNewPublisher_foreignClient(t *testing.T) {
+	source := Config{
+		ClientID:     "sourceClientID",
+		ClientSecret: "sourceClientSecret",
+		OAuth: OAuthConfig{
+			GrantType:    GrantRefreshToken,
+			Scopes:       []string{"saved_content_read"},
+			RefreshToken: "sourceRefreshToken",
+		},
+		Publish: PublishConfig{AccountID: 300},
+	}
+
+	// the source client keeps its OAuth settings
+	require.NoError(t, source.Validate())
+	p := NewPublisher(source, http.DefaultClient, cache.New(5*time.Minute, 10*time.Minute), nil).(*publisher)
+	require.Equal(t, source.OAuth, p.client.config.OAuth)
+
+	// another client gets none of them, and cannot be used with the refresh_token grant
+	target := source
+	target.Publish.ClientID = "targetClientID"
+	target.Publish.ClientSecret = "targetClientSecret"
+	require.ErrorContains(t, target.Validate(), "client_credentials")
+
+	target.OAuth.GrantType = GrantClientCredentials
+	require.NoError(t, target.Validate())
+	p = NewPublisher(target, http.DefaultClient, cache.New(5*time.Minute, 10*time.Minute), nil).(*publisher)
+	require.Equal(t, "targetClientID", p.client.config.ClientID)
+	require.Equal(t, "targetClientSecret", p.client.config.ClientSecret)
+	require.Equal(t, OAuthConfig{GrantType: GrantClientCredentials}, p.client.config.OAuth)
+}
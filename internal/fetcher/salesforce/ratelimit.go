@@ -0,0 +1,122 @@
+package salesforce
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrAPICallBudgetExceeded is returned once a run made the configured number of API calls
+var ErrAPICallBudgetExceeded = errors.New("SFMC API call budget exceeded")
+
+type RateLimitConfig struct {
+	// RequestsPerSecond is the sustained rate of API calls, zero disables rate limiting
+	RequestsPerSecond float64 `env:"SALESFORCE_RATE_LIMIT_RPS" envDefault:"0"`
+	// Burst is the number of calls allowed at once above the sustained rate
+	Burst int `env:"SALESFORCE_RATE_LIMIT_BURST" envDefault:"1"`
+	// CallBudget is the maximum number of API calls per run, zero means unlimited
+	CallBudget int `env:"SALESFORCE_API_CALL_BUDGET" envDefault:"0"`
+}
+
+// rateLimiter is a token bucket shared by every call of the clients of a session
+type rateLimiter struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newRateLimiter(config RateLimitConfig) *rateLimiter {
+	burst := float64(max(config.Burst, 1))
+	return &rateLimiter{
+		rate:   config.RequestsPerSecond,
+		burst:  burst,
+		tokens: burst,
+		last:   time.Now(),
+	}
+}
+
+// wait blocks until the next call may be made
+func (l *rateLimiter) wait(ctx context.Context) error {
+	if l == nil || l.rate <= 0 {
+		return nil
+	}
+
+	l.mu.Lock()
+
+	// refill the bucket for the elapsed time, then reserve a token
+	now := time.Now()
+	l.tokens = min(l.burst, l.tokens+now.Sub(l.last).Seconds()*l.rate)
+	l.last = now
+	l.tokens--
+	var delay time.Duration
+	if l.tokens < 0 {
+		delay = time.Duration(-l.tokens / l.rate * float64(time.Second))
+	}
+	l.mu.Unlock()
+
+	if delay == 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// callBudget counts the calls of a client against the budget of its current run.
+// Clients sharing a rate limit keep their own budget, they run on their own schedule.
+type callBudget struct {
+	mu     sync.Mutex
+	budget int
+	calls  int
+}
+
+func newCallBudget(budget int) *callBudget {
+	return &callBudget{budget: budget}
+}
+
+// spend counts a call, or fails once the budget of the run is spent
+func (b *callBudget) spend() error {
+	if b == nil {
+		return nil
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.budget > 0 && b.calls >= b.budget {
+		return ErrAPICallBudgetExceeded
+	}
+	b.calls++
+	return nil
+}
+
+// startRun resets the call budget at the beginning of a run,
+// a run may fetch several times e.g. to detect deletions
+func (b *callBudget) startRun() {
+	if b == nil {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.calls = 0
+}
+
+// callsMade returns the number of calls made in the current run
+func (b *callBudget) callsMade() int {
+	if b == nil {
+		return 0
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.calls
+}
@@ -0,0 +1,122 @@
+package salesforce
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/patrickmn/go-cache"
+	"github.com/stretchr/testify/require"
+
+	"jet-example/internal/domain"
+)
+
+func TestRateLimiter_wait(t *testing.T) {
+	limiter := newRateLimiter(RateLimitConfig{RequestsPerSecond: 50, Burst: 2})
+
+	start := time.Now()
+	for range 5 {
+		require.NoError(t, limiter.wait(context.Background()))
+	}
+
+	// the burst goes through at once, the 3 other calls wait 20ms each
+	require.GreaterOrEqual(t, time.Since(start), 55*time.Millisecond)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	require.ErrorIs(t, limiter.wait(ctx), context.Canceled)
+}
+
+func TestCallBudget_spend(t *testing.T) {
+	budget := newCallBudget(2)
+	require.NoError(t, budget.spend())
+	require.NoError(t, budget.spend())
+	require.ErrorIs(t, budget.spend(), ErrAPICallBudgetExceeded)
+	require.Equal(t, 2, budget.callsMade())
+
+	budget.startRun()
+	require.NoError(t, budget.spend())
+}
+
+func TestSalesforceClient_FetchContentBlocksCallBudget(t *testing.T) {
+	tests := []struct {
+		name       string
+		budget     int
+		policy     PartialFailurePolicy
+		wantBlocks int
+		wantErr    require.ErrorAssertionFunc
+	}{
+		{
+			name:       "Run within budget",
+			budget:     10,
+			wantBlocks: 10,
+			wantErr:    require.NoError,
+		},
+		{
+			name:   "Run out of budget is stopped",
+			budget: 4,
+			wantErr: func(t require.TestingT, err error, _ ...interface{}) {
+				require.ErrorIs(t, err, ErrAPICallBudgetExceeded)
+				require.False(t, domain.IsPartialResult(err))
+			},
+		},
+		{
+			name:   "Run out of budget is stopped despite best effort policy",
+			budget: 4,
+			policy: PartialFailureBestEffort,
+			wantErr: func(t require.TestingT, err error, _ ...interface{}) {
+				require.ErrorIs(t, err, ErrAPICallBudgetExceeded)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			const totalPages = 10
+			var requested atomic.Int32
+			mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var request domain.ContentBlocksRequest
+				json.NewDecoder(r.Body).Decode(&request)
+				requested.Add(1)
+
+				json.NewEncoder(w).Encode(ContentAssetsResponse{
+					Count:    totalPages,
+					Page:     request.Page.Page,
+					PageSize: 1,
+					Items:    []domain.Asset{{Content: fmt.Sprintf("Block %d", request.Page.Page)}},
+				})
+			}))
+			defer mockServer.Close()
+
+			c := &client{
+				config: Config{
+					AuthURL:              mockServer.URL,
+					MaxConcurrentPages:   2,
+					PartialFailurePolicy: tt.policy,
+				},
+				httpClient: http.DefaultClient,
+				cache:      cache.New(5*time.Minute, 10*time.Minute),
+				budget:     newCallBudget(tt.budget),
+			}
+			c.cache.Set(cacheKeyAccessTokenKey, "testAccessToken", cache.DefaultExpiration)
+			c.cache.Set(cacheKeyRestInstanceURLKey, mockServer.URL, cache.DefaultExpiration)
+
+			got, err := c.FetchContentBlocks(context.Background(), domain.ContentBlocksRequest{})
+
+			tt.wantErr(t, err)
+			require.Len(t, got, tt.wantBlocks)
+			require.LessOrEqual(t, int(requested.Load()), tt.budget)
+
+			// the budget is per run, a second fetch of the same run counts against it
+			_, err = c.FetchContentBlocks(context.Background(), domain.ContentBlocksRequest{})
+			require.ErrorIs(t, err, ErrAPICallBudgetExceeded)
+			c.StartRun()
+			require.Zero(t, c.budget.callsMade())
+		})
+	}
+}
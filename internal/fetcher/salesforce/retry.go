@@ -0,0 +1,135 @@
+package salesforce
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"time"
+
+	"jet-example/pkg/transport"
+)
+
+type RetryConfig struct {
+	// MaxAttempts includes the first attempt, values below 2 disable retries
+	MaxAttempts int           `env:"SALESFORCE_RETRY_MAX_ATTEMPTS" envDefault:"4"`
+	BaseDelay   time.Duration `env:"SALESFORCE_RETRY_BASE_DELAY" envDefault:"500ms"`
+	MaxDelay    time.Duration `env:"SALESFORCE_RETRY_MAX_DELAY" envDefault:"30s"`
+	// Jitter randomizes each delay between half and the full computed backoff
+	Jitter bool `env:"SALESFORCE_RETRY_JITTER" envDefault:"true"`
+}
+
+// StatusError is returned when SFMC answers with an unexpected status code
+type StatusError struct {
+	Operation  string
+	StatusCode int
+	// RetryAfter is the delay requested by the Retry-After header, zero if absent
+	RetryAfter time.Duration
+}
+
+func (e *StatusError) Error() string {
+	if e.StatusCode == http.StatusUnauthorized {
+		return fmt.Sprintf("failed to %s: unauthorized", e.Operation)
+	}
+	return fmt.Sprintf("failed to %s, status code: %d", e.Operation, e.StatusCode)
+}
+
+func newStatusError(operation string, httpResponse *http.Response) *StatusError {
+	return &StatusError{
+		Operation:  operation,
+		StatusCode: httpResponse.StatusCode,
+		RetryAfter: transport.ParseRetryAfter(httpResponse.Header.Get("Retry-After"), time.Now()),
+	}
+}
+
+// isRetryable reports whether a failed call may succeed when repeated:
+// throttling (429), server errors (5xx) and timeouts are, anything else is permanent
+func isRetryable(err error) bool {
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode == http.StatusTooManyRequests ||
+			statusErr.StatusCode >= http.StatusInternalServerError
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+
+	return false
+}
+
+// isUnauthorized reports whether SFMC rejected the access token
+func isUnauthorized(err error) bool {
+	var statusErr *StatusError
+	return errors.As(err, &statusErr) && statusErr.StatusCode == http.StatusUnauthorized
+}
+
+// backoff returns the delay before the given retry (1 based),
+// a Retry-After from the server takes precedence over the exponential delay
+func (r RetryConfig) backoff(retry int, err error) time.Duration {
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) && statusErr.RetryAfter > 0 {
+		if r.MaxDelay > 0 {
+			return min(statusErr.RetryAfter, r.MaxDelay)
+		}
+		return statusErr.RetryAfter
+	}
+
+	delay := r.BaseDelay << (retry - 1)
+	if delay < r.BaseDelay { // shift overflow
+		delay = r.MaxDelay
+	}
+	if r.MaxDelay > 0 && delay > r.MaxDelay {
+		delay = r.MaxDelay
+	}
+	if r.Jitter && delay > 1 {
+		delay = delay/2 + rand.N(delay/2)
+	}
+	return delay
+}
+
+// withoutRetry calls operation once, for calls SFMC may have processed before they failed
+// e.g. creating an asset twice. Like withRetry it counts against the budget and waits
+// for the rate limiter first.
+func (c *client) withoutRetry(ctx context.Context, operation func() error) error {
+	if err := c.budget.spend(); err != nil {
+		return err
+	}
+	if err := c.shared().limiter.wait(ctx); err != nil {
+		return err
+	}
+	return operation()
+}
+
+// withRetry calls operation until it succeeds, fails permanently,
+// runs out of attempts or the context is done.
+// Every attempt is an API call, so it counts against the budget and waits for the rate limiter first.
+func (c *client) withRetry(ctx context.Context, operation func() error) error {
+	for attempt := 1; ; attempt++ {
+		if err := c.budget.spend(); err != nil {
+			return err
+		}
+		if err := c.shared().limiter.wait(ctx); err != nil {
+			return err
+		}
+
+		err := operation()
+		if err == nil {
+			return nil
+		}
+		if attempt >= c.config.Retry.MaxAttempts || !isRetryable(err) || ctx.Err() != nil {
+			return err
+		}
+
+		timer := time.NewTimer(c.config.Retry.backoff(attempt, err))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return errors.Join(err, ctx.Err())
+		case <-timer.C:
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package salesforce
+
+import (
+	"context"
+	"errors"
+	"iter"
+
+	"jet-example/internal/domain"
+)
+
+// StreamContentBlocks yields content blocks page by page as they are fetched,
+// so the catalog never has to be held in memory. Failed pages are reported
+// at the end of the sequence according to the partial failure policy.
+func (c *client) StreamContentBlocks(
+	ctx context.Context,
+	request domain.ContentBlocksRequest,
+) iter.Seq2[domain.Asset, error] {
+	return func(yield func(domain.Asset, error) bool) {
+		if err := c.validate(request); err != nil {
+			yield(domain.Asset{}, err)
+			return
+		}
+		stopTokenRefresher := c.startTokenRefresher(ctx)
+		defer stopTokenRefresher()
+
+		var totalPages int
+		var failedPages []domain.PageError
+		stopped := false
+		for _, accountID := range c.config.accountIDs() {
+			businessUnitPages, err := c.fetchBusinessUnitPages(ctx, accountID, request, func(result pageResult) bool {
+				if result.err != nil {
+					failedPages = append(failedPages, result.pageError(accountID))
+					return !c.config.failsFast() && !errors.Is(result.err, ErrAPICallBudgetExceeded)
+				}
+				for _, asset := range result.contentBlocks {
+					if !yield(asset, nil) {
+						stopped = true
+						return false
+					}
+				}
+				return true
+			})
+			if stopped {
+				return
+			}
+			if err != nil {
+				yield(domain.Asset{}, err)
+				return
+			}
+			totalPages += businessUnitPages
+
+			if len(failedPages) > 0 && (c.config.failsFast() || exceedsBudget(failedPages)) {
+				break
+			}
+		}
+
+		// a run out of budget is stopped whatever the partial failure policy
+		if exceedsBudget(failedPages) {
+			yield(domain.Asset{}, c.budgetError())
+			return
+		}
+
+		if err := c.config.partialResultError(totalPages, failedPages); err != nil {
+			yield(domain.Asset{}, err)
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package salesforce
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/patrickmn/go-cache"
+	"github.com/stretchr/testify/require"
+
+	"jet-example/internal/domain"
+)
+
+func TestSalesforceClient_StreamContentBlocks(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  PartialFailurePolicy
+		handler http.HandlerFunc
+		take    int // stop consuming after this many assets, 0 consumes everything
+		want    []domain.Asset
+		wantErr require.ErrorAssertionFunc
+	}{
+		{
+			name:    "Best effort streams fetched pages then reports the missing ones",
+			handler: secondPageFailsHandler,
+			policy:  PartialFailureBestEffort,
+			want:    []domain.Asset{{Content: "Block 1"}, {Content: "Block 2"}},
+			wantErr: func(t require.TestingT, err error, _ ...interface{}) {
+				require.True(t, domain.IsPartialResult(err))
+			},
+		},
+		{
+			name:    "Failed page ends the stream when failing fast",
+			handler: secondPageFailsHandler,
+			want:    []domain.Asset{{Content: "Block 1"}, {Content: "Block 2"}},
+			wantErr: func(t require.TestingT, err error, _ ...interface{}) {
+				require.Error(t, err)
+				require.False(t, domain.IsPartialResult(err))
+			},
+		},
+		{
+			name:    "Consumer stops early",
+			handler: secondPageFailsHandler,
+			take:    1,
+			want:    []domain.Asset{{Content: "Block 1"}},
+			wantErr: require.NoError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockServer := httptest.NewServer(tt.handler)
+			defer mockServer.Close()
+
+			c := &client{
+				config: Config{
+					AuthURL:              mockServer.URL,
+					PartialFailurePolicy: tt.policy,
+				},
+				httpClient: http.DefaultClient,
+				cache:      cache.New(5*time.Minute, 10*time.Minute),
+			}
+			c.cache.Set(cacheKeyAccessTokenKey, "testAccessToken", cache.DefaultExpiration)
+			c.cache.Set(cacheKeyRestInstanceURLKey, mockServer.URL, cache.DefaultExpiration)
+
+			var got []domain.Asset
+			var gotErr error
+			request := domain.ContentBlocksRequest{Page: domain.Page{PageSize: 2}}
+			for asset, err := range c.StreamContentBlocks(context.Background(), request) {
+				if err != nil {
+					gotErr = err
+					break
+				}
+				got = append(got, asset)
+				if len(got) == tt.take {
+					break
+				}
+			}
+
+			tt.wantErr(t, gotErr)
+			require.Equal(t, tt.want, withoutRaw(got))
+		})
+	}
+}
+
+func TestSalesforceClient_StreamContentBlocksStalledConsumer(t *testing.T) {
+	const totalPages = 20
+	const maxConcurrentPages = 2
+
+	var requests atomic.Int32
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		var request domain.ContentBlocksRequest
+		json.NewDecoder(r.Body).Decode(&request)
+		json.NewEncoder(w).Encode(ContentAssetsResponse{
+			Count:    totalPages,
+			Page:     request.Page.Page,
+			PageSize: 1,
+			Items:    []domain.Asset{{ID: request.Page.Page}},
+		})
+	}))
+	defer mockServer.Close()
+
+	c := &client{
+		config: Config{
+			AuthURL:            mockServer.URL,
+			MaxConcurrentPages: maxConcurrentPages,
+		},
+		httpClient: http.DefaultClient,
+		cache:      cache.New(5*time.Minute, 10*time.Minute),
+	}
+	c.cache.Set(cacheKeyAccessTokenKey, "testAccessToken", cache.DefaultExpiration)
+	c.cache.Set(cacheKeyRestInstanceURLKey, mockServer.URL, cache.DefaultExpiration)
+
+	request := domain.ContentBlocksRequest{Page: domain.Page{PageSize: 1}}
+	for range c.StreamContentBlocks(context.Background(), request) {
+		// while the consumer is stalled, only the pages of the window are fetched ahead
+		require.Never(t, func() bool {
+			return requests.Load() > 1+maxConcurrentPages
+		}, 100*time.Millisecond, time.Millisecond)
+		break
+	}
+	require.LessOrEqual(t, requests.Load(), int32(1+maxConcurrentPages))
+}
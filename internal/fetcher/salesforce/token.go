@@ -4,6 +4,14 @@ type TokenRequest struct {
 	GrantType    string `json:"grant_type"`
 	ClientID     string `json:"client_id"`
 	ClientSecret string `json:"client_secret"`
+	// AccountID is the MID of the business unit, the default business unit is used when omitted
+	AccountID int `json:"account_id,omitempty"`
+	// Scope is the space separated list of requested scopes, all of the package when omitted
+	Scope        string `json:"scope,omitempty"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	// Code and RedirectURI are only sent with the authorization_code grant
+	Code        string `json:"code,omitempty"`
+	RedirectURI string `json:"redirect_uri,omitempty"`
 }
 
 type TokenResponse struct {
@@ -11,4 +19,8 @@ type TokenResponse struct {
 	ExpiresIn   int    `json:"expires_in"`
 	// TokenType       string `json:"token_type"` -> Always "Bearer"
 	RestInstanceURL string `json:"rest_instance_url"`
+	// Scope is the space separated list of granted scopes
+	Scope string `json:"scope,omitempty"`
+	// RefreshToken is only issued to web app packages, it replaces the one the request used
+	RefreshToken string `json:"refresh_token,omitempty"`
 }
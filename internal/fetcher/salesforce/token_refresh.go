@@ -0,0 +1,97 @@
+package salesforce
+
+import (
+	"context"
+	"log"
+	"strconv"
+	"time"
+)
+
+type TokenRefreshConfig struct {
+	// Background renews the access tokens during a fetch before they expire,
+	// so pages fetched late in a long run never wait for a new token
+	Background bool `env:"SALESFORCE_TOKEN_REFRESH_BACKGROUND" envDefault:"false"`
+	// Ahead is how long before the cached token expires it is renewed
+	Ahead time.Duration `env:"SALESFORCE_TOKEN_REFRESH_AHEAD" envDefault:"5m"`
+	// Interval is how often the cached tokens are checked
+	Interval time.Duration `env:"SALESFORCE_TOKEN_REFRESH_INTERVAL" envDefault:"30s"`
+}
+
+// Defaults applied when the durations are not set
+const (
+	defaultTokenRefreshAhead    = 5 * time.Minute
+	defaultTokenRefreshInterval = 30 * time.Second
+)
+
+func (c TokenRefreshConfig) ahead() time.Duration {
+	if c.Ahead <= 0 {
+		return defaultTokenRefreshAhead
+	}
+	return c.Ahead
+}
+
+func (c TokenRefreshConfig) interval() time.Duration {
+	if c.Interval <= 0 {
+		return defaultTokenRefreshInterval
+	}
+	return c.Interval
+}
+
+// startTokenRefresher renews the tokens of the business units in the background
+// until the returned function is called, which waits for the refresher to stop.
+// It does nothing unless enabled.
+func (c *client) startTokenRefresher(ctx context.Context) (stop func()) {
+	config := c.config.TokenRefresh
+	if !config.Background {
+		return func() {}
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(config.interval())
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				c.renewExpiringTokens(ctx, config.ahead())
+			}
+		}
+	}()
+
+	return func() {
+		cancel()
+		<-done
+	}
+}
+
+// renewExpiringTokens requests a new token for every business unit whose cached token
+// expires within ahead. Tokens not cached are left to the next caller.
+func (c *client) renewExpiringTokens(ctx context.Context, ahead time.Duration) {
+	for _, accountID := range c.config.accountIDs() {
+		if ctx.Err() != nil {
+			return
+		}
+		_, expiresAt, found := c.cache.GetWithExpiration(c.tokenKey(accessTokenCacheKey(accountID)))
+		if !found || time.Until(expiresAt) > ahead {
+			continue
+		}
+
+		// callers missing the cache meanwhile wait for the renewed token, so stopping
+		// the refresher stops waiting for it but does not cancel it
+		renewal := c.shared().tokenFlight.DoChan(c.tokenKey(strconv.Itoa(accountID)), func() (TokenResponse, error) {
+			return c.requestToken(context.WithoutCancel(ctx), accountID)
+		})
+		select {
+		case result := <-renewal:
+			if result.Err != nil {
+				log.Printf("failed to renew access token: %v", c.businessUnitError(accountID, result.Err))
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
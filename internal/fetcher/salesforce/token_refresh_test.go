@@ -0,0 +1,193 @@
+package salesforce
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/patrickmn/go-cache"
+	"github.com/stretchr/testify/require"
+)
+
+// newTokenServer serves a new token on every request, expiring after expiresIn seconds
+func newTokenServer(t *testing.T, expiresIn int, requests *atomic.Int32) *httptest.Server {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		count := requests.Add(1)
+		time.Sleep(10 * time.Millisecond) // leave time for concurrent callers to pile up
+		json.NewEncoder(w).Encode(TokenResponse{
+			AccessToken:     "token" + strconv.Itoa(int(count)),
+			ExpiresIn:       expiresIn,
+			RestInstanceURL: "instanceURL",
+		})
+	}))
+	t.Cleanup(mockServer.Close)
+	return mockServer
+}
+
+func TestSalesforceClient_fetchAccessTokenConcurrent(t *testing.T) {
+	var requests atomic.Int32
+	mockServer := newTokenServer(t, 1200, &requests)
+
+	c := &client{
+		config:     Config{AuthURL: mockServer.URL},
+		httpClient: http.DefaultClient,
+		cache:      cache.New(5*time.Minute, 10*time.Minute),
+	}
+
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			tokenResponse, err := c.fetchAccessToken(context.Background(), defaultAccountID)
+			require.NoError(t, err)
+			require.Equal(t, "token1", tokenResponse.AccessToken)
+		}()
+	}
+	wg.Wait()
+
+	require.Equal(t, int32(1), requests.Load())
+
+	// the token is cached until the expiry margin
+	_, expiresAt, found := c.cache.GetWithExpiration(accessTokenCacheKey(defaultAccountID))
+	require.True(t, found)
+	require.WithinDuration(t, time.Now().Add(1200*time.Second-tokenExpiryMargin), expiresAt, 5*time.Second)
+}
+
+func TestSalesforceClient_fetchAccessTokenCancelled(t *testing.T) {
+	var requests atomic.Int32
+	requested := make(chan struct{}, 1)
+	release := make(chan struct{})
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		requested <- struct{}{}
+		<-release
+		json.NewEncoder(w).Encode(TokenResponse{AccessToken: "token1", ExpiresIn: 1200, RestInstanceURL: "instanceURL"})
+	}))
+	defer mockServer.Close()
+
+	c := &client{
+		config:     Config{AuthURL: mockServer.URL},
+		httpClient: http.DefaultClient,
+		cache:      cache.New(5*time.Minute, 10*time.Minute),
+	}
+
+	// the caller running the lookup is cancelled while another one waits for it
+	ctx, cancel := context.WithCancel(context.Background())
+	results := make(chan error, 2)
+	go func() {
+		_, err := c.fetchAccessToken(ctx, defaultAccountID)
+		results <- err
+	}()
+	<-requested
+	go func() {
+		_, err := c.fetchAccessToken(context.Background(), defaultAccountID)
+		results <- err
+	}()
+	time.Sleep(10 * time.Millisecond) // leave time for the second caller to wait
+	// the cancelled caller returns at once, while the lookup is still blocked
+	cancel()
+	require.ErrorIs(t, <-results, context.Canceled)
+	close(release)
+
+	// the lookup goes on for the caller still waiting
+	require.NoError(t, <-results)
+	require.Equal(t, int32(1), requests.Load())
+}
+
+func TestSalesforceClient_fetchAccessTokenWithinMargin(t *testing.T) {
+	var requests atomic.Int32
+	mockServer := newTokenServer(t, 60, &requests)
+
+	c := &client{
+		config:     Config{AuthURL: mockServer.URL},
+		httpClient: http.DefaultClient,
+		cache:      cache.New(5*time.Minute, 10*time.Minute),
+	}
+
+	// a token expiring within the margin is used once but never cached
+	_, err := c.fetchAccessToken(context.Background(), defaultAccountID)
+	require.NoError(t, err)
+	_, found := c.getCachedToken(defaultAccountID)
+	require.False(t, found)
+}
+
+func TestSalesforceClient_renewExpiringTokens(t *testing.T) {
+	tests := []struct {
+		name         string
+		expiresIn    time.Duration
+		wantRequests int32
+		wantToken    string
+	}{
+		{
+			name:         "Token expiring soon is renewed",
+			expiresIn:    time.Minute,
+			wantRequests: 1,
+			wantToken:    "token1",
+		},
+		{
+			name:         "Token far from expiry is kept",
+			expiresIn:    10 * time.Minute,
+			wantRequests: 0,
+			wantToken:    "cachedToken",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests atomic.Int32
+			mockServer := newTokenServer(t, 1200, &requests)
+
+			c := &client{
+				config:     Config{AuthURL: mockServer.URL},
+				httpClient: http.DefaultClient,
+				cache:      cache.New(5*time.Minute, 10*time.Minute),
+			}
+			c.cache.Set(accessTokenCacheKey(defaultAccountID), "cachedToken", tt.expiresIn)
+			c.cache.Set(restInstanceURLCacheKey(defaultAccountID), "instanceURL", tt.expiresIn)
+
+			c.renewExpiringTokens(context.Background(), 5*time.Minute)
+
+			require.Equal(t, tt.wantRequests, requests.Load())
+			tokenResponse, found := c.getCachedToken(defaultAccountID)
+			require.True(t, found)
+			require.Equal(t, tt.wantToken, tokenResponse.AccessToken)
+		})
+	}
+}
+
+func TestSalesforceClient_startTokenRefresher(t *testing.T) {
+	var requests atomic.Int32
+	mockServer := newTokenServer(t, 1200, &requests)
+
+	c := &client{
+		config: Config{
+			AuthURL: mockServer.URL,
+			TokenRefresh: TokenRefreshConfig{
+				Background: true,
+				Ahead:      5 * time.Minute,
+				Interval:   time.Millisecond,
+			},
+		},
+		httpClient: http.DefaultClient,
+		cache:      cache.New(5*time.Minute, 10*time.Minute),
+	}
+	c.cache.Set(accessTokenCacheKey(defaultAccountID), "cachedToken", time.Minute)
+	c.cache.Set(restInstanceURLCacheKey(defaultAccountID), "instanceURL", time.Minute)
+
+	stop := c.startTokenRefresher(context.Background())
+	require.Eventually(t, func() bool {
+		tokenResponse, _ := c.getCachedToken(defaultAccountID)
+		return tokenResponse.AccessToken == "token1"
+	}, time.Second, time.Millisecond)
+	stop()
+
+	// the renewed token is far from expiry, so it is not renewed again
+	require.Equal(t, int32(1), requests.Load())
+}
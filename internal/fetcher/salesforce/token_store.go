@@ -0,0 +1,108 @@
+package salesforce
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"jet-example/internal/domain"
+)
+
+// The process cache is checked first, the shared token store only when it misses,
+// so a token store outage costs an extra token request rather than failing the run.
+
+// tokenExpiryMargin is how long before its expiry a token is no longer used,
+// API documentations recommend that we refresh our token two minutes before its lifetime ends.
+const tokenExpiryMargin = 2 * time.Minute
+
+// tokenStoreKey identifies the token of a business unit and scopes across processes
+func (c *client) tokenStoreKey(accountID int) string {
+	return c.tokenKey(businessUnitCacheKey(c.config.ClientID, accountID))
+}
+
+// getStoredToken loads a token another run or process saved, if it is still valid
+func (c *client) getStoredToken(ctx context.Context, accountID int) (TokenResponse, bool) {
+	if c.tokenStore == nil {
+		return TokenResponse{}, false
+	}
+
+	token, err := c.tokenStore.GetToken(ctx, c.tokenStoreKey(accountID))
+	if err != nil {
+		log.Printf("failed to load access token from token store: %v", err)
+		return TokenResponse{}, false
+	}
+	if !token.ValidFor(tokenExpiryMargin) {
+		return TokenResponse{}, false
+	}
+
+	return TokenResponse{
+		AccessToken:     token.AccessToken,
+		RestInstanceURL: token.InstanceURL,
+		ExpiresIn:       int(time.Until(token.ExpiresAt).Seconds()),
+	}, true
+}
+
+// saveStoredToken shares a new token with other runs and processes
+func (c *client) saveStoredToken(ctx context.Context, accountID int, tokenResponse TokenResponse) {
+	if c.tokenStore == nil {
+		return
+	}
+
+	token := domain.AccessToken{
+		AccessToken: tokenResponse.AccessToken,
+		InstanceURL: tokenResponse.RestInstanceURL,
+		ExpiresAt:   time.Now().Add(time.Duration(tokenResponse.ExpiresIn) * time.Second),
+	}
+	if err := c.tokenStore.SaveToken(ctx, c.tokenStoreKey(accountID), token); err != nil {
+		log.Printf("failed to save access token to token store: %v", err)
+	}
+}
+
+// evictStoredToken removes a token SFMC rejected, unless another process already
+// replaced it with a new one
+func (c *client) evictStoredToken(ctx context.Context, accountID int, rejectedToken string) {
+	if c.tokenStore == nil {
+		return
+	}
+
+	key := c.tokenStoreKey(accountID)
+	token, err := c.tokenStore.GetToken(ctx, key)
+	if err != nil || token.AccessToken != rejectedToken {
+		return
+	}
+
+	if err := c.tokenStore.DeleteToken(ctx, key); err != nil {
+		log.Printf("failed to delete access token from token store: %v", err)
+	}
+}
+
+// refreshTokenKey identifies the refresh token of the client across processes. One refresh
+// token serves every business unit and scope, each use rotates it for all of them.
+func (c *client) refreshTokenKey() string {
+	return c.config.ClientID + ":" + cacheKeyRefreshTokenKey
+}
+
+// getStoredRefreshToken loads the refresh token last rotated by any run or process
+func (c *client) getStoredRefreshToken(ctx context.Context) domain.AccessToken {
+	if c.tokenStore == nil {
+		return domain.AccessToken{}
+	}
+
+	token, err := c.tokenStore.GetToken(ctx, c.refreshTokenKey())
+	if err != nil {
+		log.Printf("failed to load refresh token from token store: %v", err)
+		return domain.AccessToken{}
+	}
+	return token
+}
+
+// saveStoredRefreshToken shares a rotated refresh token with other runs and processes
+func (c *client) saveStoredRefreshToken(ctx context.Context, token domain.AccessToken) {
+	if c.tokenStore == nil {
+		return
+	}
+
+	if err := c.tokenStore.SaveToken(ctx, c.refreshTokenKey(), token); err != nil {
+		log.Printf("failed to save refresh token to token store: %v", err)
+	}
+}
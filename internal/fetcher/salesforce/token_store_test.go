@@ -0,0 +1,115 @@
+package salesforce
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/patrickmn/go-cache"
+	"github.com/stretchr/testify/require"
+
+	"jet-example/internal/domain"
+)
+
+type fakeTokenStore struct {
+	mu     sync.Mutex
+	tokens map[string]domain.AccessToken
+}
+
+func (s *fakeTokenStore) GetToken(ctx context.Context, key string) (domain.AccessToken, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.tokens[key], nil
+}
+
+func (s *fakeTokenStore) SaveToken(ctx context.Context, key string, token domain.AccessToken) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tokens[key] = token
+	return nil
+}
+
+func (s *fakeTokenStore) DeleteToken(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.tokens, key)
+	return nil
+}
+
+func TestSalesforceClient_fetchAccessTokenTokenStore(t *testing.T) {
+	tests := []struct {
+		name          string
+		storedToken   domain.AccessToken
+		expectedToken string
+		wantRequests  int32
+	}{
+		{
+			name: "Valid stored token is reused",
+			storedToken: domain.AccessToken{
+				AccessToken: "storedToken",
+				InstanceURL: "storedInstanceURL",
+				ExpiresAt:   time.Now().Add(time.Hour),
+			},
+			expectedToken: "storedToken",
+			wantRequests:  0,
+		},
+		{
+			name: "Token about to expire is replaced",
+			storedToken: domain.AccessToken{
+				AccessToken: "storedToken",
+				InstanceURL: "storedInstanceURL",
+				ExpiresAt:   time.Now().Add(time.Minute),
+			},
+			expectedToken: "newToken",
+			wantRequests:  1,
+		},
+		{
+			name:          "Missing token is requested and saved",
+			expectedToken: "newToken",
+			wantRequests:  1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests atomic.Int32
+			mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests.Add(1)
+				json.NewEncoder(w).Encode(TokenResponse{
+					AccessToken:     "newToken",
+					ExpiresIn:       3600,
+					RestInstanceURL: "newInstanceURL",
+				})
+			}))
+			defer mockServer.Close()
+
+			store := &fakeTokenStore{tokens: map[string]domain.AccessToken{}}
+			if tt.storedToken.AccessToken != "" {
+				store.tokens["clientID"] = tt.storedToken
+			}
+			c := &client{
+				config:     Config{AuthURL: mockServer.URL, ClientID: "clientID"},
+				httpClient: http.DefaultClient,
+				cache:      cache.New(5*time.Minute, 10*time.Minute),
+				tokenStore: store,
+			}
+
+			got, err := c.fetchAccessToken(context.Background(), defaultAccountID)
+
+			require.NoError(t, err)
+			require.Equal(t, tt.expectedToken, got.AccessToken)
+			require.Equal(t, tt.wantRequests, requests.Load())
+			require.Equal(t, tt.expectedToken, store.tokens["clientID"].AccessToken)
+
+			// later calls are served by the process cache
+			_, err = c.fetchAccessToken(context.Background(), defaultAccountID)
+			require.NoError(t, err)
+			require.Equal(t, tt.wantRequests, requests.Load())
+		})
+	}
+}
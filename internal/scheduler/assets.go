@@ -0,0 +1,73 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+
+	"jet-example/internal/domain"
+)
+
+// AssetRef identifies an asset to sync by its ID or, when the ID is zero, by its customer key
+type AssetRef struct {
+	ID          int
+	CustomerKey string
+}
+
+func (r AssetRef) String() string {
+	if r.ID != 0 {
+		return "asset " + strconv.Itoa(r.ID)
+	}
+	return strconv.Quote(r.CustomerKey)
+}
+
+// SyncAssets fetches the given assets and uploads them one by one, e.g. to re-sync a block
+// after a marketer edited it. The content blocks snapshot and the watermark are left untouched
+// and a failed asset does not stop the others.
+func (s *Scheduler) SyncAssets(ctx context.Context, refs []AssetRef) error {
+	assetGetter, ok := s.fetcher.(domain.AssetGetter)
+	if !ok {
+		return errors.New("fetcher does not support single assets")
+	}
+	assetUploader, ok := s.uploader.(domain.AssetUploader)
+	if !ok {
+		return errors.New("uploader does not support single assets")
+	}
+
+	s.startRun()
+
+	var errs []error
+	var binaryAssets []domain.Asset
+	for _, ref := range refs {
+		asset, err := getAsset(ctx, assetGetter, ref)
+		if err == nil {
+			err = assetUploader.UploadAsset(ctx, asset)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to sync %s: %w", ref, err))
+			continue
+		}
+
+		log.Printf("synced %s", ref)
+		if asset.FileProperties != nil {
+			binaryAssets = append(binaryAssets, asset)
+		}
+	}
+
+	if s.config.DownloadFiles && len(binaryAssets) > 0 {
+		if err := s.syncAssetFiles(ctx, binaryAssets); err != nil {
+			errs = append(errs, fmt.Errorf("failed to sync asset files: %w", err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func getAsset(ctx context.Context, assetGetter domain.AssetGetter, ref AssetRef) (domain.Asset, error) {
+	if ref.ID != 0 {
+		return assetGetter.GetAsset(ctx, ref.ID)
+	}
+	return assetGetter.GetAssetByKey(ctx, ref.CustomerKey)
+}
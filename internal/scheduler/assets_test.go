@@ -0,0 +1,68 @@
+package scheduler
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"jet-example/internal/domain"
+)
+
+type fakeAssetGetter struct {
+	fakeFetcher
+	assets []domain.Asset
+}
+
+func (f *fakeAssetGetter) GetAsset(_ context.Context, id int) (domain.Asset, error) {
+	for _, asset := range f.assets {
+		if asset.ID == id {
+			return asset, nil
+		}
+	}
+	return domain.Asset{}, domain.ErrAssetNotFound
+}
+
+func (f *fakeAssetGetter) GetAssetByKey(_ context.Context, customerKey string) (domain.Asset, error) {
+	for _, asset := range f.assets {
+		if asset.CustomerKey == customerKey {
+			return asset, nil
+		}
+	}
+	return domain.Asset{}, domain.ErrAssetNotFound
+}
+
+type fakeAssetUploader struct {
+	fakeUploader
+	assets []domain.Asset
+}
+
+func (u *fakeAssetUploader) UploadAsset(_ context.Context, asset domain.Asset) error {
+	u.assets = append(u.assets, asset)
+	return nil
+}
+
+func TestScheduler_SyncAssets(t *testing.T) {
+	fetcher := &fakeAssetGetter{assets: []domain.Asset{
+		{ID: 1, CustomerKey: "header"},
+		{ID: 2, CustomerKey: "footer"},
+	}}
+	uploader := &fakeAssetUploader{}
+	store := &fakeWatermarkStore{}
+	s := NewScheduler(Config{}, fetcher, uploader, store)
+
+	err := s.SyncAssets(context.Background(), []AssetRef{
+		{ID: 1},
+		{CustomerKey: "footer"},
+		{ID: 3},
+	})
+
+	// the missing asset is reported, the others are synced anyway
+	require.ErrorIs(t, err, domain.ErrAssetNotFound)
+	require.ErrorContains(t, err, "asset 3")
+	require.Equal(t, []domain.Asset{{ID: 1, CustomerKey: "header"}, {ID: 2, CustomerKey: "footer"}}, uploader.assets)
+
+	// the snapshot and the watermark are left untouched
+	require.Empty(t, uploader.uploaded)
+	require.Zero(t, store.saved)
+}
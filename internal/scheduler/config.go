@@ -0,0 +1,14 @@
+package scheduler
+
+type Config struct {
+	// FullSync ignores the stored watermark and fetches the entire catalog
+	FullSync bool `env:"SYNC_FULL" envDefault:"false"`
+	// DownloadFiles also stores the files of binary assets e.g. images and documents
+	DownloadFiles bool `env:"SYNC_DOWNLOAD_FILES" envDefault:"false"`
+	// DetectDeletions stores tombstones of the assets deleted since the previous run
+	DetectDeletions bool `env:"SYNC_DETECT_DELETIONS" envDefault:"false"`
+	// DependencyGraph stores which content blocks include each other with AMPscript
+	DependencyGraph bool `env:"SYNC_DEPENDENCY_GRAPH" envDefault:"false"`
+	// Streaming uploads content blocks while they are fetched, when both the fetcher and the uploader support it
+	Streaming bool `env:"SYNC_STREAMING" envDefault:"true"`
+}
@@ -0,0 +1,71 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"jet-example/internal/domain"
+)
+
+// indexFields are the only fields fetched to list the assets of a delta run
+var indexFields = []string{"id", "customerKey", "name"}
+
+// detectDeletions compares the assets of this run with the ones of the previous run
+// and stores a tombstone for every asset that disappeared. A delta run only fetched the
+// modified assets, so the complete list is fetched again with the index fields only.
+// Assets leaving the configured filters are reported as deleted as well.
+func (s *Scheduler) detectDeletions(ctx context.Context, summary syncSummary, fullFetch bool) error {
+	store, ok := s.uploader.(domain.TombstoneStore)
+	if !ok {
+		return errors.New("uploader does not support tombstones")
+	}
+
+	current := summary.index
+	if !fullFetch {
+		var err error
+		if current, err = s.fetchAssetIndex(ctx); err != nil {
+			return err
+		}
+	}
+
+	previous, err := store.GetAssetIndex(ctx)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for i := range current {
+		current[i].LastSeen = now
+	}
+
+	// the first run has nothing to compare with
+	if previous != nil {
+		tombstones := domain.DetectDeletions(previous, current, now)
+		if len(tombstones) > 0 {
+			if err := store.UploadTombstones(ctx, tombstones); err != nil {
+				return err
+			}
+			log.Printf("detected %d deleted assets", len(tombstones))
+		}
+	}
+
+	return store.SaveAssetIndex(ctx, current)
+}
+
+// fetchAssetIndex lists every asset, a partial result is an error
+// as the missing pages would be taken for deleted assets
+func (s *Scheduler) fetchAssetIndex(ctx context.Context) ([]domain.IndexedAsset, error) {
+	assets, err := s.fetcher.FetchContentBlocks(ctx, domain.ContentBlocksRequest{Fields: indexFields})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list assets: %w", err)
+	}
+
+	index := make([]domain.IndexedAsset, 0, len(assets))
+	for _, asset := range assets {
+		index = append(index, domain.IndexAsset(asset, time.Time{}))
+	}
+	return index, nil
+}
@@ -0,0 +1,93 @@
+package scheduler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"jet-example/internal/domain"
+)
+
+type fakeTombstoneStore struct {
+	fakeUploader
+	index      []domain.IndexedAsset
+	tombstones []domain.Tombstone
+}
+
+func (u *fakeTombstoneStore) GetAssetIndex(_ context.Context) ([]domain.IndexedAsset, error) {
+	return u.index, nil
+}
+
+func (u *fakeTombstoneStore) SaveAssetIndex(_ context.Context, index []domain.IndexedAsset) error {
+	u.index = index
+	return nil
+}
+
+func (u *fakeTombstoneStore) UploadTombstones(_ context.Context, tombstones []domain.Tombstone) error {
+	u.tombstones = append(u.tombstones, tombstones...)
+	return nil
+}
+
+func TestScheduler_detectDeletions(t *testing.T) {
+	lastRun := time.Date(2024, 12, 1, 0, 0, 0, 0, time.UTC)
+	previousIndex := []domain.IndexedAsset{
+		{ID: 1, Name: "Header", LastSeen: lastRun},
+		{ID: 2, Name: "Footer", LastSeen: lastRun},
+	}
+
+	tests := []struct {
+		name           string
+		watermark      time.Time
+		index          []domain.IndexedAsset
+		wantRequests   int
+		wantTombstones []int
+	}{
+		{
+			name:           "Full run compares the fetched assets",
+			index:          previousIndex,
+			wantRequests:   1,
+			wantTombstones: []int{2},
+		},
+		{
+			name:           "Delta run lists every asset",
+			watermark:      lastRun,
+			index:          previousIndex,
+			wantRequests:   2,
+			wantTombstones: []int{2},
+		},
+		{
+			name:         "First run only stores the index",
+			wantRequests: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fetcher := &fakeFetcher{contentBlocks: []domain.Asset{{ID: 1, Name: "Header"}}}
+			uploader := &fakeTombstoneStore{index: tt.index}
+			store := &fakeWatermarkStore{watermark: tt.watermark}
+			s := NewScheduler(Config{DetectDeletions: true}, fetcher, uploader, store)
+
+			err := s.fetchAndSyncContentBlocks(context.Background())
+
+			require.NoError(t, err)
+			require.Len(t, fetcher.requests, tt.wantRequests)
+			if tt.wantRequests > 1 {
+				require.Equal(t, indexFields, fetcher.requests[1].Fields)
+			}
+
+			var tombstoneIDs []int
+			for _, tombstone := range uploader.tombstones {
+				require.Equal(t, lastRun, tombstone.LastSeenSnapshot)
+				tombstoneIDs = append(tombstoneIDs, tombstone.ID)
+			}
+			require.Equal(t, tt.wantTombstones, tombstoneIDs)
+
+			require.Len(t, uploader.index, 1)
+			require.Equal(t, 1, uploader.index[0].ID)
+			require.False(t, uploader.index[0].LastSeen.IsZero())
+		})
+	}
+}
@@ -0,0 +1,46 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"jet-example/internal/domain"
+)
+
+// dependencyFields are the fields fetched to parse the references of a delta run
+var dependencyFields = []string{"id", "customerKey", "name", "category", "content", "views", "slots"}
+
+// storeDependencyGraph builds the graph of the content blocks including each other and
+// stores it. A delta run only fetched the modified assets, so the content of every asset
+// is fetched again to resolve the references across the whole catalog.
+func (s *Scheduler) storeDependencyGraph(ctx context.Context, summary syncSummary, fullFetch bool) error {
+	store, ok := s.uploader.(domain.DependencyGraphStore)
+	if !ok {
+		return errors.New("uploader does not support dependency graphs")
+	}
+
+	nodes := summary.dependencies
+	if !fullFetch {
+		assets, err := s.fetcher.FetchContentBlocks(ctx, domain.ContentBlocksRequest{Fields: dependencyFields})
+		if err != nil {
+			return fmt.Errorf("failed to list assets: %w", err)
+		}
+		nodes = make([]domain.DependencyNode, 0, len(assets))
+		for _, asset := range assets {
+			nodes = append(nodes, domain.NewDependencyNode(asset))
+		}
+	}
+
+	graph := domain.BuildDependencyGraph(nodes)
+	if len(graph.Dangling) > 0 || len(graph.Cycles) > 0 {
+		log.Printf(
+			"dependency graph has %d dangling references and %d cycles",
+			len(graph.Dangling),
+			len(graph.Cycles),
+		)
+	}
+
+	return store.UploadDependencyGraph(ctx, graph)
+}
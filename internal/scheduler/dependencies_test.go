@@ -0,0 +1,66 @@
+package scheduler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"jet-example/internal/domain"
+)
+
+type fakeDependencyGraphStore struct {
+	fakeUploader
+	graphs []domain.DependencyGraph
+}
+
+func (u *fakeDependencyGraphStore) UploadDependencyGraph(_ context.Context, graph domain.DependencyGraph) error {
+	u.graphs = append(u.graphs, graph)
+	return nil
+}
+
+func TestScheduler_storeDependencyGraph(t *testing.T) {
+	tests := []struct {
+		name         string
+		watermark    time.Time
+		wantRequests int
+	}{
+		{
+			name:         "Full run parses the fetched assets",
+			wantRequests: 1,
+		},
+		{
+			name:         "Delta run fetches the content of every asset",
+			watermark:    time.Date(2024, 12, 1, 0, 0, 0, 0, time.UTC),
+			wantRequests: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fetcher := &fakeFetcher{contentBlocks: []domain.Asset{
+				{ID: 1, Content: `%%=ContentBlockByKey("footer")=%%`},
+				{ID: 2, CustomerKey: "footer", Content: `%%=ContentBlockById(3)=%%`},
+			}}
+			uploader := &fakeDependencyGraphStore{}
+			store := &fakeWatermarkStore{watermark: tt.watermark}
+			s := NewScheduler(Config{DependencyGraph: true}, fetcher, uploader, store)
+
+			err := s.fetchAndSyncContentBlocks(context.Background())
+
+			require.NoError(t, err)
+			require.Len(t, fetcher.requests, tt.wantRequests)
+			if tt.wantRequests > 1 {
+				require.Equal(t, dependencyFields, fetcher.requests[1].Fields)
+			}
+			require.Len(t, uploader.graphs, 1)
+			require.Equal(t, []domain.DependencyEdge{
+				{From: 1, To: 2, Reference: domain.ContentBlockReference{Function: domain.ContentBlockByKey, Argument: "footer"}},
+			}, uploader.graphs[0].Edges)
+			require.Equal(t, []domain.DependencyEdge{
+				{From: 2, Reference: domain.ContentBlockReference{Function: domain.ContentBlockByID, Argument: "3"}},
+			}, uploader.graphs[0].Dangling)
+		})
+	}
+}
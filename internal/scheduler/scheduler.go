@@ -2,8 +2,10 @@ package scheduler
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
+	"time"
 
 	"github.com/robfig/cron/v3"
 
@@ -11,19 +13,25 @@ import (
 )
 
 type Scheduler struct {
-	fetcher  domain.Fetcher
-	uploader domain.Uploader
-	cron     *cron.Cron
+	config         Config
+	fetcher        domain.Fetcher
+	uploader       domain.Uploader
+	watermarkStore domain.WatermarkStore
+	cron           *cron.Cron
 }
 
 func NewScheduler(
+	config Config,
 	fetcher domain.Fetcher,
 	uploader domain.Uploader,
+	watermarkStore domain.WatermarkStore,
 ) *Scheduler {
 	return &Scheduler{
-		fetcher:  fetcher,
-		uploader: uploader,
-		cron:     cron.New(),
+		config:         config,
+		fetcher:        fetcher,
+		uploader:       uploader,
+		watermarkStore: watermarkStore,
+		cron:           cron.New(),
 	}
 }
 
@@ -47,17 +55,204 @@ func (s *Scheduler) Stop() {
 	log.Println("scheduler stopped")
 }
 
+// startRun tells the fetcher and the uploader that a run begins, whatever number
+// of fetches the run makes they count against a single API call budget
+func (s *Scheduler) startRun() {
+	for _, port := range []any{s.fetcher, s.uploader} {
+		if tracker, ok := port.(domain.RunTracker); ok {
+			tracker.StartRun()
+		}
+	}
+}
+
 func (s *Scheduler) fetchAndSyncContentBlocks(ctx context.Context) error {
-	// fetch content blocks
-	contentBlocks, err := s.fetcher.FetchContentBlocks(ctx, domain.ContentBlocksRequest{})
+	s.startRun()
+
+	// load the watermark of the last successful sync, zero means full sync
+	watermark, err := s.watermarkStore.GetWatermark(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to fetch content blocks: %w", err)
+		return fmt.Errorf("failed to get watermark: %w", err)
+	}
+
+	request := domain.ContentBlocksRequest{}
+	if !s.config.FullSync && !watermark.IsZero() {
+		request.Query = domain.ModifiedAfterQuery(watermark)
+		log.Printf("syncing content blocks modified after %s", watermark)
+	} else {
+		log.Println("syncing all content blocks")
+	}
+
+	summary, err := s.syncContentBlocks(ctx, request)
+	partial := domain.IsPartialResult(err)
+	if err != nil && !partial {
+		return err
+	}
+
+	// the snapshot replaced today's one, so does its manifest
+	if manifestErr := s.uploadSnapshotManifest(ctx, err); manifestErr != nil {
+		return errors.Join(manifestErr, err)
+	}
+
+	// binary assets are streamed from the fetcher to the uploader one by one
+	if s.config.DownloadFiles {
+		if err := s.syncAssetFiles(ctx, summary.binaryAssets); err != nil {
+			return fmt.Errorf("failed to sync asset files: %w", err)
+		}
+	}
+
+	// missing pages may hold blocks older than the newest fetched one,
+	// so a partial snapshot must not advance the watermark
+	if partial {
+		return fmt.Errorf("uploaded partial snapshot, watermark not advanced: %w", err)
+	}
+
+	// advance the watermark only once the upload succeeded
+	if summary.latestModifiedDate.After(watermark) {
+		if err := s.watermarkStore.SaveWatermark(ctx, summary.latestModifiedDate); err != nil {
+			return fmt.Errorf("failed to save watermark: %w", err)
+		}
+	}
+
+	// deletions are only detected from complete snapshots
+	if s.config.DetectDeletions {
+		if err := s.detectDeletions(ctx, summary, request.Query == nil); err != nil {
+			return fmt.Errorf("failed to detect deleted assets: %w", err)
+		}
+	}
+
+	if s.config.DependencyGraph {
+		if err := s.storeDependencyGraph(ctx, summary, request.Query == nil); err != nil {
+			return fmt.Errorf("failed to store dependency graph: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// uploadSnapshotManifest marks the uploaded snapshot as complete or partial,
+// listing the failed pages of the accepted partial result fetchErr
+func (s *Scheduler) uploadSnapshotManifest(ctx context.Context, fetchErr error) error {
+	store, ok := s.uploader.(domain.SnapshotManifestStore)
+	if !ok {
+		if fetchErr != nil {
+			log.Println("uploader cannot mark snapshots, the partial snapshot is not told apart from a full one")
+		}
+		return nil
+	}
+
+	if err := store.UploadSnapshotManifest(ctx, domain.NewSnapshotManifest(fetchErr, time.Now())); err != nil {
+		return fmt.Errorf("failed to mark snapshot: %w", err)
+	}
+	return nil
+}
+
+// syncSummary is what the scheduler keeps of the content blocks once they are uploaded
+type syncSummary struct {
+	latestModifiedDate time.Time
+	binaryAssets       []domain.Asset
+	// index lists every fetched asset to detect deletions
+	index []domain.IndexedAsset
+	// dependencies are the parsed references of every fetched asset, when tracked
+	dependencies      []domain.DependencyNode
+	trackDependencies bool
+}
+
+func (s *syncSummary) add(asset domain.Asset) {
+	if asset.ModifiedDate.After(s.latestModifiedDate) {
+		s.latestModifiedDate = asset.ModifiedDate
+	}
+	s.index = append(s.index, domain.IndexAsset(asset, time.Time{}))
+	if s.trackDependencies {
+		s.dependencies = append(s.dependencies, domain.NewDependencyNode(asset))
+	}
+	if asset.FileProperties != nil {
+		asset.Raw = nil // only the file metadata is needed later on
+		s.binaryAssets = append(s.binaryAssets, asset)
+	}
+}
+
+// syncContentBlocks fetches and uploads the content blocks, streaming them when
+// enabled and supported by both the fetcher and the uploader. An accepted partial
+// result is uploaded and its error returned.
+func (s *Scheduler) syncContentBlocks(
+	ctx context.Context,
+	request domain.ContentBlocksRequest,
+) (syncSummary, error) {
+	summary := syncSummary{trackDependencies: s.config.DependencyGraph}
+
+	streamingFetcher, canStreamFetch := s.fetcher.(domain.StreamingFetcher)
+	streamingUploader, canStreamUpload := s.uploader.(domain.StreamingUploader)
+	if s.config.Streaming && canStreamFetch && canStreamUpload {
+		contentBlocks := streamingFetcher.StreamContentBlocks(ctx, request)
+		err := streamingUploader.UploadContentBlockStream(ctx, func(yield func(domain.Asset, error) bool) {
+			for contentBlock, err := range contentBlocks {
+				if err == nil {
+					summary.add(contentBlock)
+				}
+				if !yield(contentBlock, err) {
+					return
+				}
+			}
+		})
+		if err != nil && !domain.IsPartialResult(err) {
+			return syncSummary{}, fmt.Errorf("failed to stream content blocks: %w", err)
+		}
+		return summary, err
+	}
+
+	// fetch content blocks
+	contentBlocks, err := s.fetcher.FetchContentBlocks(ctx, request)
+	partial := domain.IsPartialResult(err)
+	if err != nil && !partial {
+		return syncSummary{}, fmt.Errorf("failed to fetch content blocks: %w", err)
 	}
 
 	// upload to provided uploader
 	if err := s.uploader.UploadContentBlocks(ctx, contentBlocks); err != nil {
-		return fmt.Errorf("failed to upload content blocks: %w", err)
+		return syncSummary{}, fmt.Errorf("failed to upload content blocks: %w", err)
 	}
 
-	return nil
+	for _, contentBlock := range contentBlocks {
+		summary.add(contentBlock)
+	}
+	return summary, err
+}
+
+// syncAssetFiles copies the file of every binary asset, a failed file does not stop the others
+func (s *Scheduler) syncAssetFiles(ctx context.Context, assets []domain.Asset) error {
+	fileFetcher, ok := s.fetcher.(domain.AssetFileFetcher)
+	if !ok {
+		return errors.New("fetcher does not support asset files")
+	}
+	fileUploader, ok := s.uploader.(domain.AssetFileUploader)
+	if !ok {
+		return errors.New("uploader does not support asset files")
+	}
+
+	var errs []error
+	for _, asset := range assets {
+		if asset.FileProperties == nil {
+			continue
+		}
+		if err := syncAssetFile(ctx, fileFetcher, fileUploader, asset); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func syncAssetFile(
+	ctx context.Context,
+	fileFetcher domain.AssetFileFetcher,
+	fileUploader domain.AssetFileUploader,
+	asset domain.Asset,
+) error {
+	file, err := fileFetcher.OpenAssetFile(ctx, asset)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return fileUploader.UploadAssetFile(ctx, asset, file)
 }
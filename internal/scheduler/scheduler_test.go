@@ -0,0 +1,228 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"jet-example/internal/domain"
+)
+
+type fakeFetcher struct {
+	requests      []domain.ContentBlocksRequest
+	contentBlocks []domain.Asset
+	err           error
+	runs          int
+}
+
+func (f *fakeFetcher) StartRun() {
+	f.runs++
+}
+
+func (f *fakeFetcher) FetchContentBlocks(
+	_ context.Context,
+	request domain.ContentBlocksRequest,
+) ([]domain.Asset, error) {
+	f.requests = append(f.requests, request)
+	return f.contentBlocks, f.err
+}
+
+type fakeUploader struct {
+	uploaded [][]domain.Asset
+	err      error
+}
+
+func (u *fakeUploader) UploadContentBlocks(_ context.Context, contentBlocks []domain.Asset) error {
+	u.uploaded = append(u.uploaded, contentBlocks)
+	return u.err
+}
+
+type fakeWatermarkStore struct {
+	watermark time.Time
+	saved     int
+}
+
+func (s *fakeWatermarkStore) GetWatermark(_ context.Context) (time.Time, error) {
+	return s.watermark, nil
+}
+
+func (s *fakeWatermarkStore) SaveWatermark(_ context.Context, watermark time.Time) error {
+	s.watermark = watermark
+	s.saved++
+	return nil
+}
+
+func TestScheduler_fetchAndSyncContentBlocks(t *testing.T) {
+	lastRun := time.Date(2024, 12, 1, 0, 0, 0, 0, time.UTC)
+	modified := lastRun.Add(time.Hour)
+
+	tests := []struct {
+		name          string
+		config        Config
+		watermark     time.Time
+		fetcher       *fakeFetcher
+		uploader      *fakeUploader
+		wantQuery     *domain.Query
+		wantWatermark time.Time
+		wantSaved     int
+		wantUploads   int
+		wantErr       require.ErrorAssertionFunc
+	}{
+		{
+			name:          "First run - full sync and watermark stored",
+			fetcher:       &fakeFetcher{contentBlocks: []domain.Asset{{Content: "Block 1", ModifiedDate: modified}}},
+			uploader:      &fakeUploader{},
+			wantQuery:     nil,
+			wantWatermark: modified,
+			wantSaved:     1,
+			wantUploads:   1,
+			wantErr:       require.NoError,
+		},
+		{
+			name:          "Incremental run - query built from watermark",
+			watermark:     lastRun,
+			fetcher:       &fakeFetcher{contentBlocks: []domain.Asset{{Content: "Block 1", ModifiedDate: modified}}},
+			uploader:      &fakeUploader{},
+			wantQuery:     domain.ModifiedAfterQuery(lastRun),
+			wantWatermark: modified,
+			wantSaved:     1,
+			wantUploads:   1,
+			wantErr:       require.NoError,
+		},
+		{
+			name:          "Incremental run - nothing changed keeps watermark",
+			watermark:     lastRun,
+			fetcher:       &fakeFetcher{},
+			uploader:      &fakeUploader{},
+			wantQuery:     domain.ModifiedAfterQuery(lastRun),
+			wantWatermark: lastRun,
+			wantSaved:     0,
+			wantUploads:   1,
+			wantErr:       require.NoError,
+		},
+		{
+			name:          "Full sync requested - watermark ignored",
+			config:        Config{FullSync: true},
+			watermark:     lastRun,
+			fetcher:       &fakeFetcher{contentBlocks: []domain.Asset{{Content: "Block 1", ModifiedDate: modified}}},
+			uploader:      &fakeUploader{},
+			wantQuery:     nil,
+			wantWatermark: modified,
+			wantSaved:     1,
+			wantUploads:   1,
+			wantErr:       require.NoError,
+		},
+		{
+			name:      "Partial result - uploaded but watermark not advanced",
+			watermark: lastRun,
+			fetcher: &fakeFetcher{
+				contentBlocks: []domain.Asset{{Content: "Block 1", ModifiedDate: modified}},
+				err: &domain.PartialResultError{
+					TotalPages:  2,
+					FailedPages: []domain.PageError{{Page: 2}},
+					Accepted:    true,
+				},
+			},
+			uploader:      &fakeUploader{},
+			wantQuery:     domain.ModifiedAfterQuery(lastRun),
+			wantWatermark: lastRun,
+			wantSaved:     0,
+			wantUploads:   1,
+			wantErr:       require.Error,
+		},
+		{
+			name:      "Rejected partial result - nothing uploaded",
+			watermark: lastRun,
+			fetcher: &fakeFetcher{
+				err: &domain.PartialResultError{TotalPages: 2, FailedPages: []domain.PageError{{Page: 2}}},
+			},
+			uploader:      &fakeUploader{},
+			wantQuery:     domain.ModifiedAfterQuery(lastRun),
+			wantWatermark: lastRun,
+			wantSaved:     0,
+			wantUploads:   0,
+			wantErr:       require.Error,
+		},
+		{
+			name:          "Upload failure - watermark not advanced",
+			watermark:     lastRun,
+			fetcher:       &fakeFetcher{contentBlocks: []domain.Asset{{Content: "Block 1", ModifiedDate: modified}}},
+			uploader:      &fakeUploader{err: errors.New("upload failed")},
+			wantQuery:     domain.ModifiedAfterQuery(lastRun),
+			wantWatermark: lastRun,
+			wantSaved:     0,
+			wantUploads:   1,
+			wantErr:       require.Error,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := &fakeWatermarkStore{watermark: tt.watermark}
+			s := NewScheduler(tt.config, tt.fetcher, tt.uploader, store)
+
+			err := s.fetchAndSyncContentBlocks(context.Background())
+
+			tt.wantErr(t, err)
+			require.Len(t, tt.fetcher.requests, 1)
+			require.Equal(t, 1, tt.fetcher.runs)
+			require.Equal(t, tt.wantQuery, tt.fetcher.requests[0].Query)
+			require.Equal(t, tt.wantWatermark, store.watermark)
+			require.Equal(t, tt.wantSaved, store.saved)
+			require.Len(t, tt.uploader.uploaded, tt.wantUploads)
+		})
+	}
+}
+
+type fakeSnapshotManifestStore struct {
+	fakeUploader
+	manifests []domain.SnapshotManifest
+}
+
+func (u *fakeSnapshotManifestStore) UploadSnapshotManifest(_ context.Context, manifest domain.SnapshotManifest) error {
+	u.manifests = append(u.manifests, manifest)
+	return nil
+}
+
+func TestScheduler_uploadSnapshotManifest(t *testing.T) {
+	tests := []struct {
+		name      string
+		fetchErr  error
+		wantErr   require.ErrorAssertionFunc
+		wantPages []domain.FailedPage
+	}{
+		{
+			name:    "Full snapshot is marked complete",
+			wantErr: require.NoError,
+		},
+		{
+			name: "Partial snapshot lists the failed pages",
+			fetchErr: &domain.PartialResultError{
+				TotalPages:  2,
+				FailedPages: []domain.PageError{{Page: 2, Err: errors.New("timeout")}},
+				Accepted:    true,
+			},
+			wantErr:   require.Error,
+			wantPages: []domain.FailedPage{{Page: 2, Error: "timeout"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fetcher := &fakeFetcher{contentBlocks: []domain.Asset{{Content: "Block 1"}}, err: tt.fetchErr}
+			uploader := &fakeSnapshotManifestStore{}
+			s := NewScheduler(Config{}, fetcher, uploader, &fakeWatermarkStore{})
+
+			err := s.fetchAndSyncContentBlocks(context.Background())
+
+			tt.wantErr(t, err)
+			require.Len(t, uploader.uploaded, 1)
+			require.Len(t, uploader.manifests, 1)
+			require.Equal(t, tt.fetchErr != nil, uploader.manifests[0].Partial)
+			require.Equal(t, tt.wantPages, uploader.manifests[0].FailedPages)
+		})
+	}
+}
@@ -0,0 +1,205 @@
+package local
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"jet-example/internal/domain"
+)
+
+// keySize is the size of the AES-256 key the tokens file is encrypted with
+const keySize = 32
+
+type localStore struct {
+	filePath string
+	aead     cipher.AEAD
+	// mu serializes the read-modify-write of the file within the process,
+	// the lock file between processes
+	mu sync.Mutex
+}
+
+// NewTokenStore implement TokenStore and keeps the tokens in a file on local machine,
+// which several processes may share. The file is encrypted with AES-256-GCM using
+// encryptionKey, 32 random bytes encoded in base64 e.g. from `openssl rand -base64 32`.
+func NewTokenStore(filePath string, encryptionKey string) (domain.TokenStore, error) {
+	if encryptionKey == "" {
+		return nil, errors.New("an encryption key is required to store tokens in a file")
+	}
+
+	// a passphrase would need a key derivation function, a random key does not
+	key, err := base64.StdEncoding.DecodeString(encryptionKey)
+	if err != nil || len(key) != keySize {
+		return nil, fmt.Errorf("the encryption key must be %d random bytes encoded in base64", keySize)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+
+	return &localStore{
+		filePath: filePath,
+		aead:     aead,
+	}, nil
+}
+
+func (s *localStore) GetToken(ctx context.Context, key string) (domain.AccessToken, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	unlock, err := s.lockFile()
+	if err != nil {
+		return domain.AccessToken{}, err
+	}
+	defer unlock()
+
+	tokens, err := s.load()
+	if err != nil {
+		return domain.AccessToken{}, err
+	}
+	return tokens[key], nil
+}
+
+func (s *localStore) SaveToken(ctx context.Context, key string, token domain.AccessToken) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	unlock, err := s.lockFile()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	tokens, err := s.load()
+	if err != nil {
+		return err
+	}
+	tokens[key] = token
+	return s.save(tokens)
+}
+
+func (s *localStore) DeleteToken(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	unlock, err := s.lockFile()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	tokens, err := s.load()
+	if err != nil {
+		return err
+	}
+	if _, found := tokens[key]; !found {
+		return nil
+	}
+	delete(tokens, key)
+	return s.save(tokens)
+}
+
+// load decrypts the tokens file, a missing file means no token has been stored yet
+func (s *localStore) load() (map[string]domain.AccessToken, error) {
+	tokens := map[string]domain.AccessToken{}
+
+	data, err := os.ReadFile(s.filePath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return tokens, nil
+		}
+		return nil, fmt.Errorf("failed to read tokens file: %w", err)
+	}
+
+	nonceSize := s.aead.NonceSize()
+	if len(data) < nonceSize {
+		return nil, errors.New("failed to decrypt tokens file: file too short")
+	}
+	plaintext, err := s.aead.Open(nil, data[:nonceSize], data[nonceSize:], nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt tokens file: %w", err)
+	}
+
+	if err := json.Unmarshal(plaintext, &tokens); err != nil {
+		return nil, fmt.Errorf("failed to decode tokens file: %w", err)
+	}
+
+	return tokens, nil
+}
+
+// save encrypts the tokens which have not expired yet, writes them to a temporary file
+// readable by the owner only and renames it so a crash never leaves a truncated file behind.
+// It is called with the file locked.
+func (s *localStore) save(tokens map[string]domain.AccessToken) error {
+	for key, token := range tokens {
+		if token.Expired() {
+			delete(tokens, key)
+		}
+	}
+
+	plaintext, err := json.Marshal(tokens)
+	if err != nil {
+		return fmt.Errorf("failed to encode tokens: %w", err)
+	}
+
+	nonce := make([]byte, s.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	data := s.aead.Seal(nonce, nonce, plaintext, nil)
+
+	// each writer has its own temporary file, the lock file orders the renames
+	tmpFile, err := os.CreateTemp(filepath.Dir(s.filePath), filepath.Base(s.filePath)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to write tokens file: %w", err)
+	}
+	defer os.Remove(tmpFile.Name())
+
+	if _, err := tmpFile.Write(data); err != nil {
+		tmpFile.Close()
+		return fmt.Errorf("failed to write tokens file: %w", err)
+	}
+	if err := tmpFile.Close(); err != nil {
+		return fmt.Errorf("failed to write tokens file: %w", err)
+	}
+
+	if err := os.Rename(tmpFile.Name(), s.filePath); err != nil {
+		return fmt.Errorf("failed to replace tokens file: %w", err)
+	}
+
+	return nil
+}
+
+// lockFile locks the tokens file against the other processes until unlock is called.
+// The lock is held on a lock file next to it, the tokens file is replaced on every save.
+func (s *localStore) lockFile() (unlock func(), err error) {
+	if err := os.MkdirAll(filepath.Dir(s.filePath), 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create tokens directory: %w", err)
+	}
+
+	lockFile, err := os.OpenFile(s.filePath+".lock", os.O_CREATE|os.O_RDWR, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open tokens lock file: %w", err)
+	}
+	if err := flock(lockFile); err != nil {
+		lockFile.Close()
+		return nil, fmt.Errorf("failed to lock tokens file: %w", err)
+	}
+
+	return func() {
+		funlock(lockFile)
+		lockFile.Close()
+	}, nil
+}
@@ -0,0 +1,118 @@
+package local
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"jet-example/internal/domain"
+)
+
+// testKey is a valid encryption key, 32 bytes in base64
+var testKey = base64.StdEncoding.EncodeToString([]byte("0123456789abcdef0123456789abcdef"))
+
+func TestLocalStore(t *testing.T) {
+	ctx := context.Background()
+	filePath := filepath.Join(t.TempDir(), "tokens.enc")
+	token := domain.AccessToken{
+		AccessToken: "secretAccessToken",
+		InstanceURL: "https://instance.example.com",
+		ExpiresAt:   time.Now().Add(time.Hour).UTC().Truncate(time.Second),
+	}
+
+	store, err := NewTokenStore(filePath, testKey)
+	require.NoError(t, err)
+
+	// a missing file means no token
+	got, err := store.GetToken(ctx, "client")
+	require.NoError(t, err)
+	require.Zero(t, got)
+
+	require.NoError(t, store.SaveToken(ctx, "client", token))
+	require.NoError(t, store.SaveToken(ctx, "expired", domain.AccessToken{AccessToken: "old", ExpiresAt: time.Now()}))
+
+	// the token never reaches the disk in clear
+	data, err := os.ReadFile(filePath)
+	require.NoError(t, err)
+	require.NotContains(t, string(data), token.AccessToken)
+
+	// another process with the same key reads it back
+	other, err := NewTokenStore(filePath, testKey)
+	require.NoError(t, err)
+	got, err = other.GetToken(ctx, "client")
+	require.NoError(t, err)
+	require.True(t, token.ExpiresAt.Equal(got.ExpiresAt))
+	require.Equal(t, token.AccessToken, got.AccessToken)
+
+	got, err = other.GetToken(ctx, "expired")
+	require.NoError(t, err)
+	require.Zero(t, got)
+
+	// a wrong key cannot decrypt the file
+	wrongKey, err := NewTokenStore(filePath, base64.StdEncoding.EncodeToString(make([]byte, 32)))
+	require.NoError(t, err)
+	_, err = wrongKey.GetToken(ctx, "client")
+	require.Error(t, err)
+
+	require.NoError(t, store.DeleteToken(ctx, "client"))
+	got, err = store.GetToken(ctx, "client")
+	require.NoError(t, err)
+	require.Zero(t, got)
+
+	// a refresh token outlives its access token
+	refreshed := domain.AccessToken{AccessToken: "old", ExpiresAt: time.Now(), RefreshToken: "refresh"}
+	require.NoError(t, store.SaveToken(ctx, "refreshed", refreshed))
+	require.NoError(t, store.SaveToken(ctx, "client", token))
+	got, err = other.GetToken(ctx, "refreshed")
+	require.NoError(t, err)
+	require.Equal(t, "refresh", got.RefreshToken)
+
+	_, err = NewTokenStore(filePath, "")
+	require.Error(t, err)
+	// a passphrase is not a key
+	_, err = NewTokenStore(filePath, "passphrase")
+	require.Error(t, err)
+}
+
+func TestLocalStore_concurrentProcesses(t *testing.T) {
+	ctx := context.Background()
+	filePath := filepath.Join(t.TempDir(), "tokens.enc")
+
+	// every store stands for a process, each one saving its own tokens
+	var wg sync.WaitGroup
+	for process := range 4 {
+		store, err := NewTokenStore(filePath, testKey)
+		require.NoError(t, err)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range 10 {
+				key := fmt.Sprintf("client%d:%d", process, i)
+				require.NoError(t, store.SaveToken(ctx, key, domain.AccessToken{RefreshToken: key}))
+			}
+		}()
+	}
+	wg.Wait()
+
+	// no update is lost and no temporary file is left behind
+	store, err := NewTokenStore(filePath, testKey)
+	require.NoError(t, err)
+	for process := range 4 {
+		for i := range 10 {
+			key := fmt.Sprintf("client%d:%d", process, i)
+			got, err := store.GetToken(ctx, key)
+			require.NoError(t, err)
+			require.Equal(t, key, got.RefreshToken)
+		}
+	}
+	tmpFiles, err := filepath.Glob(filePath + ".*.tmp")
+	require.NoError(t, err)
+	require.Empty(t, tmpFiles)
+}
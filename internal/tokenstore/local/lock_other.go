@@ -0,0 +1,15 @@
+//go:build !unix
+
+package local
+
+import "os"
+
+// flock does not lock the file on platforms without flock,
+// only the processes of Unix systems can share a tokens file safely
+func flock(file *os.File) error {
+	return nil
+}
+
+func funlock(file *os.File) error {
+	return nil
+}
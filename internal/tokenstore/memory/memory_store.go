@@ -0,0 +1,47 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"github.com/patrickmn/go-cache"
+
+	"jet-example/internal/domain"
+)
+
+const keyPrefix = "tokenStore:"
+
+type memoryStore struct {
+	cache *cache.Cache
+}
+
+// NewTokenStore implement TokenStore and keeps the tokens in the process memory,
+// so they are only reused within a single process
+func NewTokenStore(cache *cache.Cache) domain.TokenStore {
+	return &memoryStore{
+		cache: cache,
+	}
+}
+
+func (s *memoryStore) GetToken(ctx context.Context, key string) (domain.AccessToken, error) {
+	token, found := s.cache.Get(keyPrefix + key)
+	if !found {
+		return domain.AccessToken{}, nil
+	}
+	return token.(domain.AccessToken), nil
+}
+
+// SaveToken keeps the token until it expires, or for good when it holds a refresh token
+func (s *memoryStore) SaveToken(ctx context.Context, key string, token domain.AccessToken) error {
+	expiration := time.Until(token.ExpiresAt)
+	if token.RefreshToken != "" {
+		expiration = cache.NoExpiration
+	}
+	s.cache.Set(keyPrefix+key, token, expiration)
+	return nil
+}
+
+func (s *memoryStore) DeleteToken(ctx context.Context, key string) error {
+	s.cache.Delete(keyPrefix + key)
+	return nil
+}
@@ -0,0 +1,96 @@
+package s3
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+
+	"jet-example/internal/domain"
+)
+
+type s3Store struct {
+	s3Client *s3.Client
+	s3Bucket string
+	s3Prefix string
+}
+
+// NewTokenStore implement TokenStore and keeps every token as an object in S3 bucket,
+// encrypted at rest by S3 (SSE-S3)
+func NewTokenStore(
+	bucket string,
+	prefix string,
+	client *s3.Client,
+) domain.TokenStore {
+	return &s3Store{
+		s3Client: client,
+		s3Bucket: strings.Trim(bucket, "/"),
+		s3Prefix: strings.Trim(prefix, "/"),
+	}
+}
+
+// GetToken reads the token object, a missing object means no token has been stored yet
+func (s *s3Store) GetToken(ctx context.Context, key string) (domain.AccessToken, error) {
+	output, err := s.s3Client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(s.s3Bucket),
+		Key:    aws.String(s.objectKey(key)),
+	})
+	if err != nil {
+		var noSuchKey *types.NoSuchKey
+		if errors.As(err, &noSuchKey) {
+			return domain.AccessToken{}, nil
+		}
+		return domain.AccessToken{}, fmt.Errorf("failed to get token object: %w", err)
+	}
+	defer output.Body.Close()
+
+	var token domain.AccessToken
+	if err := json.NewDecoder(output.Body).Decode(&token); err != nil {
+		return domain.AccessToken{}, fmt.Errorf("failed to decode token object: %w", err)
+	}
+
+	return token, nil
+}
+
+func (s *s3Store) SaveToken(ctx context.Context, key string, token domain.AccessToken) error {
+	data, err := json.Marshal(token)
+	if err != nil {
+		return fmt.Errorf("failed to encode token: %w", err)
+	}
+
+	_, err = s.s3Client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket:               aws.String(s.s3Bucket),
+		Key:                  aws.String(s.objectKey(key)),
+		Body:                 bytes.NewReader(data),
+		ServerSideEncryption: types.ServerSideEncryptionAes256,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to put token object: %w", err)
+	}
+
+	return nil
+}
+
+func (s *s3Store) DeleteToken(ctx context.Context, key string) error {
+	_, err := s.s3Client.DeleteObject(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(s.s3Bucket),
+		Key:    aws.String(s.objectKey(key)),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete token object: %w", err)
+	}
+
+	return nil
+}
+
+// objectKey is the key of the token object e.g. tokens/<client ID>:<MID>.json
+func (s *s3Store) objectKey(key string) string {
+	return path.Join(s.s3Prefix, key+".json")
+}
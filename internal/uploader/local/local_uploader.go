@@ -20,7 +20,7 @@ func NewLocalUploader() (domain.Uploader, error) {
 // UploadContentBlocks unimplemented - ideally store data to local machine
 func (u *localUploader) UploadContentBlocks(
 	ctx context.Context,
-	contentBlocks []domain.ContentBlock,
+	contentBlocks []domain.Asset,
 ) error {
 	return fmt.Errorf("unimplemented")
 }
@@ -0,0 +1,27 @@
+package s3
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// marshalCanonical encodes v as compact JSON with the keys of every object sorted,
+// nested raw SFMC payloads included, so identical catalogs produce identical
+// objects and checksums whatever order the API returned the properties in
+func marshalCanonical(v any) ([]byte, error) {
+	jsonData, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	// numbers are kept as written, decoding them as float64 would round large IDs
+	decoder := json.NewDecoder(bytes.NewReader(jsonData))
+	decoder.UseNumber()
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+
+	// maps are encoded with sorted keys
+	return json.Marshal(value)
+}
@@ -0,0 +1,27 @@
+package s3
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"jet-example/internal/domain"
+)
+
+// UploadDependencyGraph stores the graph next to the snapshot as JSON and as Graphviz DOT
+func (u *s3Uploader) UploadDependencyGraph(ctx context.Context, graph domain.DependencyGraph) error {
+	jsonData, err := marshalCanonical(graph)
+	if err != nil {
+		return fmt.Errorf("failed to marshal dependency graph: %w", err)
+	}
+
+	date := time.Now().Format("2006-01-02")
+	if err := u.upload(ctx, u.objectKey(date+"/dependencies.json"), jsonData); err != nil {
+		return fmt.Errorf("failed to upload dependency graph: %w", err)
+	}
+	if err := u.upload(ctx, u.objectKey(date+"/dependencies.dot"), []byte(graph.DOT())); err != nil {
+		return fmt.Errorf("failed to upload dependency graph DOT: %w", err)
+	}
+
+	return nil
+}
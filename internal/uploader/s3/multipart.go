@@ -0,0 +1,113 @@
+package s3
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// multipartPartSize is the amount of data buffered before a part is sent,
+// S3 requires every part but the last one to be at least 5 MiB
+const multipartPartSize = 8 << 20
+
+// multipartWriter writes an object of unknown size through a multipart upload,
+// holding at most one part in memory
+type multipartWriter struct {
+	ctx      context.Context
+	s3Client *s3.Client
+	bucket   string
+	key      string
+	uploadID string
+	buffer   bytes.Buffer
+	parts    []types.CompletedPart
+}
+
+func newMultipartWriter(
+	ctx context.Context,
+	client *s3.Client,
+	bucket string,
+	key string,
+) (*multipartWriter, error) {
+	output, err := client.CreateMultipartUpload(ctx, &s3.CreateMultipartUploadInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create multipart upload: %w", err)
+	}
+
+	return &multipartWriter{
+		ctx:      ctx,
+		s3Client: client,
+		bucket:   bucket,
+		key:      key,
+		uploadID: aws.ToString(output.UploadId),
+	}, nil
+}
+
+func (w *multipartWriter) Write(p []byte) (int, error) {
+	w.buffer.Write(p)
+	for w.buffer.Len() >= multipartPartSize {
+		if err := w.uploadPart(w.buffer.Next(multipartPartSize)); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+// Close sends the remaining data as the last part and completes the upload
+func (w *multipartWriter) Close() error {
+	if w.buffer.Len() > 0 || len(w.parts) == 0 {
+		if err := w.uploadPart(w.buffer.Bytes()); err != nil {
+			return err
+		}
+	}
+
+	_, err := w.s3Client.CompleteMultipartUpload(w.ctx, &s3.CompleteMultipartUploadInput{
+		Bucket:          aws.String(w.bucket),
+		Key:             aws.String(w.key),
+		UploadId:        aws.String(w.uploadID),
+		MultipartUpload: &types.CompletedMultipartUpload{Parts: w.parts},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to complete multipart upload: %w", err)
+	}
+	return nil
+}
+
+// Abort discards the parts sent so far, no object is created
+func (w *multipartWriter) Abort() error {
+	_, err := w.s3Client.AbortMultipartUpload(context.WithoutCancel(w.ctx), &s3.AbortMultipartUploadInput{
+		Bucket:   aws.String(w.bucket),
+		Key:      aws.String(w.key),
+		UploadId: aws.String(w.uploadID),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to abort multipart upload: %w", err)
+	}
+	return nil
+}
+
+func (w *multipartWriter) uploadPart(part []byte) error {
+	partNumber := int32(len(w.parts) + 1)
+	output, err := w.s3Client.UploadPart(w.ctx, &s3.UploadPartInput{
+		Bucket:     aws.String(w.bucket),
+		Key:        aws.String(w.key),
+		UploadId:   aws.String(w.uploadID),
+		PartNumber: aws.Int32(partNumber),
+		Body:       bytes.NewReader(part),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to upload part %d: %w", partNumber, err)
+	}
+
+	w.parts = append(w.parts, types.CompletedPart{
+		ETag:       output.ETag,
+		PartNumber: aws.Int32(partNumber),
+	})
+	return nil
+}
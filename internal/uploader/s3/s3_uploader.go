@@ -3,12 +3,17 @@ package s3
 import (
 	"bytes"
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"iter"
+	"os"
+	"path"
 	"strings"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
 
 	"jet-example/internal/domain"
@@ -34,26 +39,121 @@ func NewS3Uploader(
 
 func (u *s3Uploader) UploadContentBlocks(
 	ctx context.Context,
-	contentBlocks []domain.ContentBlock,
+	contentBlocks []domain.Asset,
 ) error {
-	jsonData, err := json.Marshal(contentBlocks)
+	jsonData, err := marshalCanonical(contentBlocks)
 	if err != nil {
 		return err
 	}
 
-	objectKey := fmt.Sprintf(
+	err = u.upload(ctx, u.contentBlocksKey(), jsonData)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// UploadContentBlockStream encodes content blocks into the snapshot object while they
+// are fetched, producing the same JSON array as UploadContentBlocks
+func (u *s3Uploader) UploadContentBlockStream(
+	ctx context.Context,
+	contentBlocks iter.Seq2[domain.Asset, error],
+) error {
+	writer, err := newMultipartWriter(ctx, u.s3Client, u.s3Bucket, u.contentBlocksKey())
+	if err != nil {
+		return err
+	}
+
+	fetchErr, err := encodeJSONArray(writer, contentBlocks)
+	if err == nil && fetchErr != nil && !domain.IsPartialResult(fetchErr) {
+		err = fetchErr
+	}
+	if err != nil {
+		if abortErr := writer.Abort(); abortErr != nil {
+			return errors.Join(err, abortErr)
+		}
+		return err
+	}
+
+	if err := writer.Close(); err != nil {
+		return err
+	}
+
+	return fetchErr
+}
+
+// encodeJSONArray writes the content blocks as a JSON array, one at a time.
+// It returns the error ending the sequence apart from errors writing the array.
+func encodeJSONArray(
+	writer io.Writer,
+	contentBlocks iter.Seq2[domain.Asset, error],
+) (fetchErr error, err error) {
+	if _, err := io.WriteString(writer, "["); err != nil {
+		return nil, err
+	}
+
+	first := true
+	for contentBlock, seqErr := range contentBlocks {
+		if seqErr != nil {
+			fetchErr = seqErr
+			break
+		}
+
+		jsonData, err := marshalCanonical(contentBlock)
+		if err != nil {
+			return nil, err
+		}
+		if !first {
+			jsonData = append([]byte(","), jsonData...)
+		}
+		first = false
+		if _, err := writer.Write(jsonData); err != nil {
+			return nil, err
+		}
+	}
+
+	if _, err := io.WriteString(writer, "]"); err != nil {
+		return nil, err
+	}
+
+	return fetchErr, nil
+}
+
+// contentBlocksKey is the key of the daily content blocks snapshot
+func (u *s3Uploader) contentBlocksKey() string {
+	return u.objectKey(fmt.Sprintf(
 		"%s/%s.json",
 		time.Now().Format("2006-01-02"),
 		"content-block",
-	)
-	err = u.upload(ctx, objectKey, jsonData)
+	))
+}
+
+// UploadSnapshotManifest stores next to the snapshot whether it is complete, the manifest
+// of a later snapshot of the same day replaces it along with the snapshot
+func (u *s3Uploader) UploadSnapshotManifest(ctx context.Context, manifest domain.SnapshotManifest) error {
+	jsonData, err := marshalCanonical(manifest)
 	if err != nil {
-		return err
+		return fmt.Errorf("failed to marshal snapshot manifest: %w", err)
+	}
+
+	objectKey := u.objectKey(fmt.Sprintf(
+		"%s/%s.json",
+		time.Now().Format("2006-01-02"),
+		"content-block.manifest",
+	))
+	if err := u.upload(ctx, objectKey, jsonData); err != nil {
+		return fmt.Errorf("failed to upload snapshot manifest: %w", err)
 	}
 
 	return nil
 }
 
+// objectKey places key under the path prefix of the uploader
+func (u *s3Uploader) objectKey(key string) string {
+	return path.Join(u.s3PathPrefix, key)
+}
+
 func (u *s3Uploader) upload(ctx context.Context, key string, body []byte) error {
 	_, err := u.s3Client.PutObject(ctx, &s3.PutObjectInput{
 		Bucket: aws.String(u.s3Bucket),
@@ -62,3 +162,94 @@ func (u *s3Uploader) upload(ctx context.Context, key string, body []byte) error
 	})
 	return err
 }
+
+// UploadAsset stores a single asset next to the content blocks snapshot
+func (u *s3Uploader) UploadAsset(ctx context.Context, asset domain.Asset) error {
+	jsonData, err := marshalCanonical(asset)
+	if err != nil {
+		return fmt.Errorf("failed to marshal asset %d: %w", asset.ID, err)
+	}
+
+	objectKey := u.objectKey(fmt.Sprintf(
+		"%s/assets/%d.json",
+		time.Now().Format("2006-01-02"),
+		asset.ID,
+	))
+	if err := u.upload(ctx, objectKey, jsonData); err != nil {
+		return fmt.Errorf("failed to upload asset %d: %w", asset.ID, err)
+	}
+
+	return nil
+}
+
+// UploadAssetFile streams the file of a binary asset next to the content blocks.
+// Files whose size the download does not confirm are spooled to a temporary file,
+// never held in memory.
+func (u *s3Uploader) UploadAssetFile(
+	ctx context.Context,
+	asset domain.Asset,
+	file io.Reader,
+) error {
+	if asset.FileProperties == nil {
+		return fmt.Errorf("asset %d has no file", asset.ID)
+	}
+
+	fileName := path.Base(asset.FileProperties.FileName)
+	if asset.FileProperties.FileName == "" {
+		fileName = fmt.Sprintf("%d.%s", asset.ID, asset.FileProperties.Extension)
+	}
+	objectKey := u.objectKey(fmt.Sprintf(
+		"%s/files/%d/%s",
+		time.Now().Format("2006-01-02"),
+		asset.ID,
+		fileName,
+	))
+
+	// the file size of the asset metadata may not match the downloaded bytes,
+	// S3 rejects or truncates a body of another length than announced
+	size := int64(-1)
+	if sized, ok := file.(domain.SizedReader); ok {
+		size = sized.Size()
+	}
+	if size < 0 {
+		spooled, spooledSize, err := spool(file)
+		if err != nil {
+			return fmt.Errorf("failed to spool file of asset %d: %w", asset.ID, err)
+		}
+		defer os.Remove(spooled.Name())
+		defer spooled.Close()
+		file, size = spooled, spooledSize
+	}
+
+	_, err := u.s3Client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket:        aws.String(u.s3Bucket),
+		Key:           aws.String(objectKey),
+		Body:          file,
+		ContentLength: aws.Int64(size),
+	}, s3.WithAPIOptions(v4.SwapComputePayloadSHA256ForUnsignedPayloadMiddleware))
+	if err != nil {
+		return fmt.Errorf("failed to upload file of asset %d: %w", asset.ID, err)
+	}
+
+	return nil
+}
+
+// spool copies file to a temporary file and rewinds it so its size is known
+func spool(file io.Reader) (*os.File, int64, error) {
+	spooled, err := os.CreateTemp("", "asset-file-*")
+	if err != nil {
+		return nil, 0, err
+	}
+
+	size, err := io.Copy(spooled, file)
+	if err == nil {
+		_, err = spooled.Seek(0, io.SeekStart)
+	}
+	if err != nil {
+		spooled.Close()
+		os.Remove(spooled.Name())
+		return nil, 0, err
+	}
+
+	return spooled, size, nil
+}
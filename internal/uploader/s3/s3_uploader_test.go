@@ -0,0 +1,69 @@
+package s3
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"jet-example/internal/domain"
+)
+
+func TestEncodeJSONArray(t *testing.T) {
+	contentBlocks := []domain.Asset{
+		{ID: 1, Content: "Block 1"},
+		{ID: 2, Content: "Block 2"},
+	}
+
+	// the stream produces the same snapshot as the batch upload
+	var buffer bytes.Buffer
+	fetchErr, err := encodeJSONArray(&buffer, func(yield func(domain.Asset, error) bool) {
+		for _, contentBlock := range contentBlocks {
+			if !yield(contentBlock, nil) {
+				return
+			}
+		}
+	})
+	require.NoError(t, err)
+	require.NoError(t, fetchErr)
+	want, err := marshalCanonical(contentBlocks)
+	require.NoError(t, err)
+	require.Equal(t, string(want), buffer.String())
+
+	// an error from the stream ends the array and is handed back
+	buffer.Reset()
+	streamErr := errors.New("page failed")
+	fetchErr, err = encodeJSONArray(&buffer, func(yield func(domain.Asset, error) bool) {
+		for _, contentBlock := range contentBlocks[:1] {
+			if !yield(contentBlock, nil) {
+				return
+			}
+		}
+		yield(domain.Asset{}, streamErr)
+	})
+	require.NoError(t, err)
+	require.ErrorIs(t, fetchErr, streamErr)
+	require.JSONEq(t, `[{"id": 1, "content": "Block 1"}]`, buffer.String())
+}
+
+func TestMarshalCanonical(t *testing.T) {
+	// the same asset as returned by two runs, with properties in another order
+	first := domain.Asset{ID: 1, Raw: json.RawMessage(
+		`{"id": 1, "views": {"html": {"content": "a", "meta": {"b": 2, "a": 1}}}, "legacyId": 9007199254740993}`,
+	)}
+	second := domain.Asset{ID: 1, Raw: json.RawMessage(
+		`{"legacyId": 9007199254740993, "views": {"html": {"meta": {"a": 1, "b": 2}, "content": "a"}}, "id": 1}`,
+	)}
+
+	firstJSON, err := marshalCanonical([]domain.Asset{first})
+	require.NoError(t, err)
+	secondJSON, err := marshalCanonical([]domain.Asset{second})
+	require.NoError(t, err)
+	require.Equal(t, string(firstJSON), string(secondJSON))
+
+	// large numbers are not rounded
+	require.Contains(t, string(firstJSON), `"legacyId":9007199254740993`)
+	require.Contains(t, string(firstJSON), `"meta":{"a":1,"b":2}`)
+}
@@ -0,0 +1,75 @@
+package s3
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+
+	"jet-example/internal/domain"
+)
+
+// assetIndexKey is not dated, there is only ever the index of the last run
+const assetIndexKey = "asset-index.json"
+
+// GetAssetIndex reads the index of the last run, a missing object means no run completed yet
+func (u *s3Uploader) GetAssetIndex(ctx context.Context) ([]domain.IndexedAsset, error) {
+	output, err := u.s3Client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(u.s3Bucket),
+		Key:    aws.String(u.objectKey(assetIndexKey)),
+	})
+	if err != nil {
+		var noSuchKey *types.NoSuchKey
+		if errors.As(err, &noSuchKey) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get asset index object: %w", err)
+	}
+	defer output.Body.Close()
+
+	index := []domain.IndexedAsset{}
+	if err := json.NewDecoder(output.Body).Decode(&index); err != nil {
+		return nil, fmt.Errorf("failed to decode asset index object: %w", err)
+	}
+
+	return index, nil
+}
+
+func (u *s3Uploader) SaveAssetIndex(ctx context.Context, index []domain.IndexedAsset) error {
+	jsonData, err := marshalCanonical(index)
+	if err != nil {
+		return fmt.Errorf("failed to marshal asset index: %w", err)
+	}
+
+	if err := u.upload(ctx, u.objectKey(assetIndexKey), jsonData); err != nil {
+		return fmt.Errorf("failed to upload asset index: %w", err)
+	}
+
+	return nil
+}
+
+// UploadTombstones stores the tombstones of a run next to its snapshot,
+// keyed by time so several runs a day do not replace each other's tombstones
+func (u *s3Uploader) UploadTombstones(ctx context.Context, tombstones []domain.Tombstone) error {
+	jsonData, err := marshalCanonical(tombstones)
+	if err != nil {
+		return fmt.Errorf("failed to marshal tombstones: %w", err)
+	}
+
+	now := time.Now()
+	objectKey := u.objectKey(fmt.Sprintf(
+		"%s/tombstones/%s.json",
+		now.Format("2006-01-02"),
+		now.Format("150405"),
+	))
+	if err := u.upload(ctx, objectKey, jsonData); err != nil {
+		return fmt.Errorf("failed to upload tombstones: %w", err)
+	}
+
+	return nil
+}
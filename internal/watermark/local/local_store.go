@@ -0,0 +1,70 @@
+package local
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"jet-example/internal/domain"
+)
+
+type watermark struct {
+	ModifiedDate time.Time `json:"modifiedDate"`
+}
+
+type localStore struct {
+	filePath string
+}
+
+// NewWatermarkStore implement WatermarkStore and keeps the watermark in a file on local machine
+func NewWatermarkStore(filePath string) domain.WatermarkStore {
+	return &localStore{
+		filePath: filePath,
+	}
+}
+
+// GetWatermark reads the watermark file, a missing file means no sync has completed yet
+func (s *localStore) GetWatermark(ctx context.Context) (time.Time, error) {
+	data, err := os.ReadFile(s.filePath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return time.Time{}, nil
+		}
+		return time.Time{}, fmt.Errorf("failed to read watermark file: %w", err)
+	}
+
+	var w watermark
+	if err := json.Unmarshal(data, &w); err != nil {
+		return time.Time{}, fmt.Errorf("failed to decode watermark file: %w", err)
+	}
+
+	return w.ModifiedDate, nil
+}
+
+// SaveWatermark writes the watermark to a temporary file and renames it
+// so a crash never leaves a truncated watermark behind
+func (s *localStore) SaveWatermark(ctx context.Context, modifiedDate time.Time) error {
+	data, err := json.Marshal(watermark{ModifiedDate: modifiedDate})
+	if err != nil {
+		return fmt.Errorf("failed to encode watermark: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.filePath), 0o755); err != nil {
+		return fmt.Errorf("failed to create watermark directory: %w", err)
+	}
+
+	tmpPath := s.filePath + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write watermark file: %w", err)
+	}
+
+	if err := os.Rename(tmpPath, s.filePath); err != nil {
+		return fmt.Errorf("failed to replace watermark file: %w", err)
+	}
+
+	return nil
+}
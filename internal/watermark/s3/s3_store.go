@@ -0,0 +1,81 @@
+package s3
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+
+	"jet-example/internal/domain"
+)
+
+type watermark struct {
+	ModifiedDate time.Time `json:"modifiedDate"`
+}
+
+type s3Store struct {
+	s3Client *s3.Client
+	s3Bucket string
+	s3Key    string
+}
+
+// NewWatermarkStore implement WatermarkStore and keeps the watermark as an object in S3 bucket
+func NewWatermarkStore(
+	bucket string,
+	key string,
+	client *s3.Client,
+) domain.WatermarkStore {
+	return &s3Store{
+		s3Client: client,
+		s3Bucket: strings.Trim(bucket, "/"),
+		s3Key:    strings.Trim(key, "/"),
+	}
+}
+
+// GetWatermark reads the watermark object, a missing object means no sync has completed yet
+func (s *s3Store) GetWatermark(ctx context.Context) (time.Time, error) {
+	output, err := s.s3Client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(s.s3Bucket),
+		Key:    aws.String(s.s3Key),
+	})
+	if err != nil {
+		var noSuchKey *types.NoSuchKey
+		if errors.As(err, &noSuchKey) {
+			return time.Time{}, nil
+		}
+		return time.Time{}, fmt.Errorf("failed to get watermark object: %w", err)
+	}
+	defer output.Body.Close()
+
+	var w watermark
+	if err := json.NewDecoder(output.Body).Decode(&w); err != nil {
+		return time.Time{}, fmt.Errorf("failed to decode watermark object: %w", err)
+	}
+
+	return w.ModifiedDate, nil
+}
+
+func (s *s3Store) SaveWatermark(ctx context.Context, modifiedDate time.Time) error {
+	data, err := json.Marshal(watermark{ModifiedDate: modifiedDate})
+	if err != nil {
+		return fmt.Errorf("failed to encode watermark: %w", err)
+	}
+
+	_, err = s.s3Client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket: aws.String(s.s3Bucket),
+		Key:    aws.String(s.s3Key),
+		Body:   bytes.NewReader(data),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to put watermark object: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,51 @@
+package logging
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+)
+
+// stdLogger writes to a standard library logger as "LEVEL msg key=value ..."
+type stdLogger struct {
+	logger *log.Logger
+}
+
+func NewStdLogger(logger *log.Logger) Logger {
+	return &stdLogger{logger: logger}
+}
+
+func (l *stdLogger) Debug(c context.Context, msg string, keysAndValues ...interface{}) {
+	l.print("DEBUG", msg, keysAndValues)
+}
+
+func (l *stdLogger) Info(c context.Context, msg string, keysAndValues ...interface{}) {
+	l.print("INFO", msg, keysAndValues)
+}
+
+func (l *stdLogger) Warn(c context.Context, msg string, keysAndValues ...interface{}) {
+	l.print("WARN", msg, keysAndValues)
+}
+
+func (l *stdLogger) Error(c context.Context, msg string, keysAndValues ...interface{}) {
+	l.print("ERROR", msg, keysAndValues)
+}
+
+func (l *stdLogger) Fatal(c context.Context, msg string, keysAndValues ...interface{}) {
+	l.print("FATAL", msg, keysAndValues)
+	l.logger.Fatal()
+}
+
+func (l *stdLogger) print(level, msg string, keysAndValues []interface{}) {
+	var line strings.Builder
+	line.WriteString(level + " " + msg)
+	for i := 0; i < len(keysAndValues); i += 2 {
+		if i+1 < len(keysAndValues) {
+			fmt.Fprintf(&line, " %v=%v", keysAndValues[i], keysAndValues[i+1])
+		} else {
+			fmt.Fprintf(&line, " %v", keysAndValues[i])
+		}
+	}
+	l.logger.Println(line.String())
+}
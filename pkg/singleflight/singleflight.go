@@ -0,0 +1,123 @@
+// Package singleflight deduplicates concurrent calls doing the same work, e.g.
+//
+//	var tokens singleflight.Group[Token]
+//	token, err, shared := tokens.Do(accountID, fetchToken)
+//
+// Callers arriving while a call with the same key is in flight wait for it
+// and share its result instead of calling again.
+package singleflight
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ErrPanicked is returned to the callers waiting for a call whose fn panicked,
+// the caller that ran fn panics again with the same value
+var ErrPanicked = errors.New("singleflight: call panicked")
+
+// Group runs calls by key, its zero value is ready to use
+type Group[T any] struct {
+	mu    sync.Mutex
+	calls map[string]*call[T]
+}
+
+type call[T any] struct {
+	done  chan struct{}
+	value T
+	err   error
+	// waiters is the number of callers waiting for the call
+	waiters int
+}
+
+// Result is the outcome of a call delivered by DoChan
+type Result[T any] struct {
+	Value T
+	Err   error
+	// Shared reports whether the result went to several callers
+	Shared bool
+}
+
+// Do runs fn unless a call with the same key is in flight, in which case it waits for that
+// call and returns its result. shared reports whether the result went to several callers.
+func (g *Group[T]) Do(key string, fn func() (T, error)) (value T, err error, shared bool) {
+	c, inFlight := g.join(key)
+	if inFlight {
+		<-c.done
+		return c.value, c.err, true
+	}
+
+	g.doCall(c, key, fn)
+	return c.value, c.err, g.shared(c)
+}
+
+// DoChan is like Do but runs fn in its own goroutine and delivers the result on the returned
+// channel, so a caller can stop waiting e.g. once its context is done while the call goes
+// on for the others. A panic in fn crashes the process as nobody is there to recover it.
+func (g *Group[T]) DoChan(key string, fn func() (T, error)) <-chan Result[T] {
+	results := make(chan Result[T], 1)
+	c, inFlight := g.join(key)
+	go func() {
+		if inFlight {
+			<-c.done
+			results <- Result[T]{Value: c.value, Err: c.err, Shared: true}
+			return
+		}
+
+		g.doCall(c, key, fn)
+		results <- Result[T]{Value: c.value, Err: c.err, Shared: g.shared(c)}
+	}()
+	return results
+}
+
+// join returns the call in flight for the key, or registers a new one the caller must run
+func (g *Group[T]) join(key string) (c *call[T], inFlight bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.calls == nil {
+		g.calls = make(map[string]*call[T])
+	}
+	if c, found := g.calls[key]; found {
+		c.waiters++
+		return c, true
+	}
+
+	c = &call[T]{done: make(chan struct{})}
+	g.calls[key] = c
+	return c, false
+}
+
+// shared reports whether callers waited for the call
+func (g *Group[T]) shared(c *call[T]) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return c.waiters > 0
+}
+
+// doCall runs fn and releases the key, even if fn panics: the waiting callers then
+// get ErrPanicked and the panic goes on once they are released
+func (g *Group[T]) doCall(c *call[T], key string, fn func() (T, error)) {
+	returned := false
+	defer func() {
+		if !returned {
+			var zero T
+			c.value = zero
+			if r := recover(); r != nil {
+				c.err = fmt.Errorf("%w: %v", ErrPanicked, r)
+				defer panic(r)
+			} else {
+				// fn called runtime.Goexit, which goes on by itself
+				c.err = ErrPanicked
+			}
+		}
+
+		g.mu.Lock()
+		delete(g.calls, key)
+		g.mu.Unlock()
+		close(c.done)
+	}()
+
+	c.value, c.err = fn()
+	returned = true
+}
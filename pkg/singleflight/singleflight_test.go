@@ -0,0 +1,134 @@
+package singleflight
+
+import (
+	"errors"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGroup_Do(t *testing.T) {
+	var group Group[string]
+	var calls atomic.Int32
+	release := make(chan struct{})
+
+	// the first caller blocks until every other caller is waiting on it
+	var wg sync.WaitGroup
+	results := make([]string, 10)
+	for i := range results {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			value, err, _ := group.Do("token", func() (string, error) {
+				calls.Add(1)
+				<-release
+				return "value", nil
+			})
+			require.NoError(t, err)
+			results[i] = value
+		}()
+	}
+	for {
+		group.mu.Lock()
+		inFlight := group.calls["token"]
+		allWaiting := inFlight != nil && inFlight.waiters == len(results)-1
+		group.mu.Unlock()
+		if allWaiting {
+			break
+		}
+		runtime.Gosched()
+	}
+	close(release)
+	wg.Wait()
+
+	require.Equal(t, int32(1), calls.Load())
+	for _, result := range results {
+		require.Equal(t, "value", result)
+	}
+
+	// once done the key runs again, errors are returned too
+	callErr := errors.New("failed")
+	_, err, shared := group.Do("token", func() (string, error) {
+		return "", callErr
+	})
+	require.ErrorIs(t, err, callErr)
+	require.False(t, shared)
+}
+
+func TestGroup_DoPanic(t *testing.T) {
+	var group Group[string]
+	started := make(chan struct{})
+	release := make(chan struct{})
+
+	panicked := make(chan any)
+	go func() {
+		defer func() { panicked <- recover() }()
+		group.Do("token", func() (string, error) {
+			close(started)
+			<-release
+			panic("boom")
+		})
+	}()
+
+	// a caller waiting for the call gets an error instead of the zero value
+	<-started
+	waited := make(chan error)
+	go func() {
+		_, err, shared := group.Do("token", func() (string, error) {
+			return "value", nil
+		})
+		require.True(t, shared)
+		waited <- err
+	}()
+	for {
+		group.mu.Lock()
+		waiting := group.calls["token"].waiters == 1
+		group.mu.Unlock()
+		if waiting {
+			break
+		}
+		runtime.Gosched()
+	}
+	close(release)
+
+	// the caller that ran fn panics again
+	require.Equal(t, "boom", <-panicked)
+	err := <-waited
+	require.ErrorIs(t, err, ErrPanicked)
+	require.ErrorContains(t, err, "boom")
+
+	// the key is released
+	value, err, _ := group.Do("token", func() (string, error) {
+		return "value", nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, "value", value)
+}
+
+func TestGroup_DoChan(t *testing.T) {
+	var group Group[string]
+	release := make(chan struct{})
+
+	first := group.DoChan("token", func() (string, error) {
+		<-release
+		return "value", nil
+	})
+	// the call is in flight, a second caller joins it instead of running fn
+	second := group.DoChan("token", func() (string, error) {
+		return "other", nil
+	})
+
+	// a caller may give up waiting, the call goes on for the others
+	select {
+	case <-first:
+		t.Fatal("the call is not done yet")
+	default:
+	}
+	close(release)
+
+	require.Equal(t, Result[string]{Value: "value", Shared: true}, <-first)
+	require.Equal(t, Result[string]{Value: "value", Shared: true}, <-second)
+}
@@ -0,0 +1,89 @@
+package transport
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"jet-example/pkg/logging"
+)
+
+const redacted = "REDACTED"
+
+// sensitiveHeaders never reach the logs
+var sensitiveHeaders = []string{"Authorization", "Cookie", "Set-Cookie", "Proxy-Authorization"}
+
+// sensitiveParams are query parameters holding credentials
+var sensitiveParams = []string{"access_token", "refresh_token", "client_secret", "code", "password", "token"}
+
+// Logging logs every request with its outcome and duration.
+// Credentials in headers and query parameters are redacted, bodies are never logged.
+func Logging(logger logging.Logger) Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(request *http.Request) (*http.Response, error) {
+			start := time.Now()
+			response, err := next.RoundTrip(request)
+
+			keysAndValues := []interface{}{
+				"method", request.Method,
+				"url", RedactURL(request.URL),
+				"requestId", request.Header.Get(RequestIDHeader),
+				"duration", time.Since(start),
+			}
+			if err != nil {
+				logger.Warn(request.Context(), "HTTP request failed", append(keysAndValues, "error", err)...)
+				return nil, err
+			}
+
+			logger.Debug(
+				request.Context(),
+				"HTTP request",
+				append(keysAndValues, "status", response.StatusCode, "headers", RedactHeaders(request.Header))...,
+			)
+			return response, nil
+		})
+	}
+}
+
+// RedactHeaders returns a copy of header without credentials
+func RedactHeaders(header http.Header) http.Header {
+	header = header.Clone()
+	for _, key := range sensitiveHeaders {
+		if header.Get(key) != "" {
+			header.Set(key, redacted)
+		}
+	}
+	return header
+}
+
+// RedactURL returns u as a string without credentials in its user info or query
+func RedactURL(u *url.URL) string {
+	if u == nil {
+		return ""
+	}
+
+	redactedURL := *u
+	if redactedURL.User != nil {
+		redactedURL.User = url.User(redacted)
+	}
+
+	query := redactedURL.Query()
+	for key := range query {
+		if isSensitiveParam(key) {
+			query.Set(key, redacted)
+		}
+	}
+	redactedURL.RawQuery = query.Encode()
+
+	return redactedURL.String()
+}
+
+func isSensitiveParam(key string) bool {
+	for _, param := range sensitiveParams {
+		if strings.EqualFold(key, param) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,30 @@
+package transport
+
+import (
+	"net/http"
+	"time"
+)
+
+// MetricsRecorder receives the outcome of every request, e.g. to export it to Prometheus or CloudWatch
+type MetricsRecorder interface {
+	// ObserveRequest is called once per round trip, statusCode is 0 when err is set
+	ObserveRequest(request *http.Request, statusCode int, duration time.Duration, err error)
+}
+
+// Metrics reports every request to the recorder
+func Metrics(recorder MetricsRecorder) Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(request *http.Request) (*http.Response, error) {
+			start := time.Now()
+			response, err := next.RoundTrip(request)
+
+			var statusCode int
+			if response != nil {
+				statusCode = response.StatusCode
+			}
+			recorder.ObserveRequest(request, statusCode, time.Since(start), err)
+
+			return response, err
+		})
+	}
+}
@@ -0,0 +1,135 @@
+package transport
+
+import (
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+)
+
+// IdempotentMethods are the methods retried unless RetryPolicy.Methods says otherwise,
+// repeating them has the same effect as sending them once
+var IdempotentMethods = []string{
+	http.MethodGet,
+	http.MethodHead,
+	http.MethodOptions,
+	http.MethodTrace,
+	http.MethodPut,
+	http.MethodDelete,
+}
+
+type RetryPolicy struct {
+	// MaxAttempts includes the first attempt, below 2 means no retries
+	MaxAttempts int
+	// Backoff returns the delay before the given retry, starting at 1
+	Backoff func(retry int) time.Duration
+	// MaxDelay caps the delay a Retry-After header asks for, zero means no cap
+	MaxDelay time.Duration
+	// ShouldRetry decides whether a round trip is retried, defaults to RetryTransient
+	ShouldRetry func(response *http.Response, err error) bool
+	// Methods are the request methods retried, defaults to IdempotentMethods.
+	// A POST may have been processed before it failed, e.g. creating an asset twice.
+	Methods []string
+}
+
+// RetryTransient retries network errors, throttling (429) and server errors (5xx)
+func RetryTransient(response *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+	return response.StatusCode == http.StatusTooManyRequests || response.StatusCode >= http.StatusInternalServerError
+}
+
+// Retry repeats failed round trips according to the policy, waiting as long as
+// the Retry-After header of the failed response asks for when it has one.
+// Requests with another method than the policy ones are sent once, whatever
+// their outcome, unless they carry an Idempotency-Key header.
+// Requests with a body are only retried when it can be replayed (http.Request.GetBody),
+// which is the case for the bodies created by http.NewRequest.
+//
+// Clients retrying their calls themselves, like the Salesforce fetcher, must not be
+// given a transport with Retry: every attempt of theirs would be repeated as well.
+func Retry(policy RetryPolicy) Middleware {
+	shouldRetry := policy.ShouldRetry
+	if shouldRetry == nil {
+		shouldRetry = RetryTransient
+	}
+	methods := policy.Methods
+	if methods == nil {
+		methods = IdempotentMethods
+	}
+
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(request *http.Request) (*http.Response, error) {
+			replayable := request.Body == nil || request.Body == http.NoBody || request.GetBody != nil
+			retryable := replayable && (slices.Contains(methods, request.Method) || hasIdempotencyKey(request))
+
+			for attempt := 1; ; attempt++ {
+				attemptRequest := request
+				if attempt > 1 && request.GetBody != nil {
+					body, err := request.GetBody()
+					if err != nil {
+						return nil, err
+					}
+					attemptRequest = request.Clone(request.Context())
+					attemptRequest.Body = body
+				}
+
+				response, err := next.RoundTrip(attemptRequest)
+				if attempt >= policy.MaxAttempts || !retryable || !shouldRetry(response, err) {
+					return response, err
+				}
+
+				var delay time.Duration
+				if policy.Backoff != nil {
+					delay = policy.Backoff(attempt)
+				}
+
+				// the response of the failed attempt is discarded
+				if response != nil {
+					if retryAfter := ParseRetryAfter(response.Header.Get("Retry-After"), time.Now()); retryAfter > 0 {
+						delay = retryAfter
+						if policy.MaxDelay > 0 {
+							delay = min(delay, policy.MaxDelay)
+						}
+					}
+					io.Copy(io.Discard, response.Body)
+					response.Body.Close()
+				}
+
+				timer := time.NewTimer(delay)
+				select {
+				case <-request.Context().Done():
+					timer.Stop()
+					return nil, request.Context().Err()
+				case <-timer.C:
+				}
+			}
+		})
+	}
+}
+
+// hasIdempotencyKey reports whether the server can tell the attempts of a request
+// apart, net/http retries such requests on its own as well
+func hasIdempotencyKey(request *http.Request) bool {
+	return request.Header.Get("Idempotency-Key") != "" || request.Header.Get("X-Idempotency-Key") != ""
+}
+
+// ParseRetryAfter supports both forms of the Retry-After header: delay in seconds and HTTP date.
+// It returns zero when the header is absent or invalid.
+func ParseRetryAfter(value string, now time.Time) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+	if date, err := http.ParseTime(value); err == nil && date.After(now) {
+		return date.Sub(now)
+	}
+	return 0
+}
@@ -0,0 +1,73 @@
+// Package transport composes http.RoundTripper middlewares, e.g.
+//
+//	httpClient := &http.Client{
+//		Transport: transport.Chain(
+//			http.DefaultTransport,
+//			transport.RequestID(),
+//			transport.UserAgent("jet-example"),
+//			transport.Logging(logger),
+//		),
+//	}
+package transport
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+)
+
+const RequestIDHeader = "X-Request-ID"
+
+// Middleware wraps a RoundTripper with additional behaviour
+type Middleware func(next http.RoundTripper) http.RoundTripper
+
+// RoundTripperFunc is an adapter to use ordinary functions as RoundTrippers
+type RoundTripperFunc func(request *http.Request) (*http.Response, error)
+
+func (f RoundTripperFunc) RoundTrip(request *http.Request) (*http.Response, error) {
+	return f(request)
+}
+
+// Chain wraps base with the middlewares, the first one being the outermost.
+// A nil base means http.DefaultTransport.
+func Chain(base http.RoundTripper, middlewares ...Middleware) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		base = middlewares[i](base)
+	}
+	return base
+}
+
+// UserAgent sets the User-Agent header of requests which have none
+func UserAgent(userAgent string) Middleware {
+	return setHeader("User-Agent", func() string { return userAgent })
+}
+
+// RequestID tags requests with a random X-Request-ID unless they already have one,
+// so they can be correlated with the logs of the server
+func RequestID() Middleware {
+	return setHeader(RequestIDHeader, newRequestID)
+}
+
+func setHeader(key string, value func() string) Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(request *http.Request) (*http.Response, error) {
+			if request.Header.Get(key) != "" {
+				return next.RoundTrip(request)
+			}
+
+			// a RoundTripper must not modify the request it was given
+			request = request.Clone(request.Context())
+			request.Header.Set(key, value())
+			return next.RoundTrip(request)
+		})
+	}
+}
+
+func newRequestID() string {
+	var id [16]byte
+	rand.Read(id[:])
+	return hex.EncodeToString(id[:])
+}